@@ -0,0 +1,15 @@
+#!/bin/bash
+# Replays recorded TBA webhook messages against a scouting server, signed the way TBA signs them.
+# Usage: replay.sh SECRET [URL] [MESSAGE.json ...]
+# SECRET must match TBAWebhookSecret in the server's config.yaml. URL defaults to a local server. Every recorded message is sent if none are given.
+
+secret=$1
+url=${2:-https://localhost/tbaWebhook}
+shift; shift
+cd "$(dirname "$0")"
+messages=${@:-verification.json match_score.json upcoming_match.json schedule_updated.json alliance_selection.json}
+for message in $messages; do
+    hmac=$(openssl dgst -sha256 -hmac "$secret" < "$message" | awk '{print $NF}')
+    echo "[replay.sh] Sending $message."
+    curl -ks -w " (%{http_code})\n" -H "Content-Type: application/json" -H "X-TBA-HMAC: $hmac" --data-binary "@$message" "$url"
+done
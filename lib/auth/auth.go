@@ -5,6 +5,7 @@ package auth
 
 import (
 	"EPIC-Scouting/lib/db"
+	"strconv"
 
 	"github.com/gin-contrib/sessions"
 	"github.com/gin-gonic/gin"
@@ -50,22 +51,81 @@ func CheckLogin(c *gin.Context) string {
 }
 
 /*
-SetTeam sets the team cookie
+SetTeam sets the team cookie to the teamid of the team the logged in user is working for
 */
-func SetTeam(c *gin.Context, team string) {
+func SetTeam(c *gin.Context, teamID string) {
 	session := sessions.Default(c)
-	session.Set("team", team)
+	session.Set("team", teamID)
 	session.Save()
 }
 
 /*
-CheckTeam gets the team cookie
+SetReviewing sets whether the logged in user is looking back over their team's last practice scouting session on the data pages, rather than the event the team is scouting
 */
-func CheckTeam(c *gin.Context) string {
+func SetReviewing(c *gin.Context, reviewing bool) {
+	session := sessions.Default(c)
+	session.Set("reviewing", reviewing)
+	session.Save()
+}
+
+/*
+CheckReviewing gets whether the logged in user is looking back over their team's last practice scouting session. See SetReviewing
+*/
+func CheckReviewing(c *gin.Context) bool {
+	reviewing, _ := sessions.Default(c).Get("reviewing").(bool)
+	return reviewing
+}
+
+/*
+CheckTeamID gets the teamid of the team the logged in user is working for: the team in the team cookie if they are on it, otherwise the first team they joined. SysAdmins may work for any team set in the cookie. Returns an empty string if the user is not logged in or is on no team
+*/
+func CheckTeamID(c *gin.Context) string {
+	userData, _ := db.UserQuery(CheckLogin(c))
+	if userData == nil {
+		return ""
+	}
 	session := sessions.Default(c)
-	team := session.Get("userID")
-	if team == nil {
+	if teamID, ok := session.Get("team").(string); ok && teamID != "" {
+		if _, err := db.GetMemberType(userData.UserID, teamID); err == nil {
+			return teamID
+		}
+		if _, err := db.GetTeamNumber(teamID); userData.SysAdmin && err == nil {
+			return teamID
+		}
+	}
+	teams, _ := db.GetUserTeams(userData.UserID)
+	if len(teams) == 0 {
 		return ""
 	}
-	return team.(string)
+	return teams[0]
+}
+
+/*
+CheckTeam gets the number of the team the logged in user is working for. See CheckTeamID
+*/
+func CheckTeam(c *gin.Context) string {
+	teamID := CheckTeamID(c)
+	if teamID == "" {
+		return ""
+	}
+	number, err := db.GetTeamNumber(teamID)
+	if err != nil {
+		return ""
+	}
+	return strconv.Itoa(number)
+}
+
+/*
+IsTeamAdmin checks if the logged in user may supervise a team. SysAdmins may supervise every team.
+*/
+func IsTeamAdmin(c *gin.Context, teamID string) bool {
+	userData, _ := db.UserQuery(CheckLogin(c))
+	if userData == nil {
+		return false
+	}
+	if userData.SysAdmin {
+		return true
+	}
+	userType, _ := db.GetMemberType(userData.UserID, teamID)
+	return userType == "admin"
 }
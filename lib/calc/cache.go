@@ -0,0 +1,221 @@
+package calc
+
+import (
+	"EPIC-Scouting/lib/db"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+/*
+Stats Cache Functions keep calculated results in memory so data pages don't recalculate everything on every view.
+Entries are keyed by event and invalidated whenever a competitor's scouted results, or the event's schedule, change. Once StartStatsCache has been called, invalidated entries keep being served while they are recalculated in the background
+*/
+
+/*
+TeamSummary is everything the data pages show about a team's scouted performance at an event
+*/
+type TeamSummary struct {
+	Overall     int
+	Auto        int
+	Shooting    int
+	ColorWheel  int
+	Climbing    int
+	Fouls       int
+	Stats       map[string]Stat   // See ScoreStats.
+	Breakdowns  map[string][]Stat // See TeamBreakdownStats.
+	Reliability Reliability
+	Prior       db.Prior // Ratings from before the event, which are blended into the ones above. See PriorMatches.
+}
+
+//cacheEntry is a cached result along with how to recalculate it
+type cacheEntry struct {
+	value   interface{}
+	compute func() interface{}
+	stale   bool
+}
+
+//statsCache holds every cached result, keyed by what it describes. See teamKey, matchKey and eventKey. Keys end in a slash so one key is never a prefix of another
+var statsCache = struct {
+	sync.Mutex
+	entries     map[string]*cacheEntry
+	refresh     chan string       // Keys waiting to be recalculated in the background. Nil until StartStatsCache is called.
+	generations map[string]uint64 // Counts invalidations of each prefix, so results calculated across one are never stored. See keyGeneration.
+}{entries: make(map[string]*cacheEntry), generations: make(map[string]uint64)}
+
+func init() {
+	db.OnResultChange(InvalidateResults)
+}
+
+//StartStatsCache starts recalculating invalidated results in the background
+func StartStatsCache() {
+	statsCache.Lock()
+	defer statsCache.Unlock()
+	if statsCache.refresh != nil {
+		return
+	}
+	statsCache.refresh = make(chan string, 256)
+	go refreshStats(statsCache.refresh)
+}
+
+//refreshStats recalculates stale entries as their keys arrive
+func refreshStats(refresh chan string) {
+	for key := range refresh {
+		statsCache.Lock()
+		entry, ok := statsCache.entries[key]
+		if !ok || !entry.stale {
+			statsCache.Unlock()
+			continue
+		}
+		generation := keyGeneration(key)
+		statsCache.Unlock()
+		value := entry.compute()
+		statsCache.Lock()
+		if current, ok := statsCache.entries[key]; ok && current == entry {
+			if keyGeneration(key) == generation {
+				entry.value = value
+				entry.stale = false
+			} else {
+				//the entry was invalidated again while it was recalculated, so it is calculated once more
+				select {
+				case refresh <- key:
+				default:
+					delete(statsCache.entries, key)
+				}
+			}
+		}
+		statsCache.Unlock()
+	}
+}
+
+//keyGeneration counts how many times a key has been invalidated, through any of the prefixes which cover it. Every prefix ends in a slash. The cache must be locked
+func keyGeneration(key string) uint64 {
+	var generation uint64
+	for ind, char := range key {
+		if char == '/' {
+			generation += statsCache.generations[key[:ind+1]]
+		}
+	}
+	return generation
+}
+
+//cached gets a result from the cache, calculating and storing it if it isn't there. A result is only stored if its key wasn't invalidated while it was calculated, since it may already be out of date
+func cached(key string, compute func() interface{}) interface{} {
+	statsCache.Lock()
+	entry, ok := statsCache.entries[key]
+	if ok {
+		value := entry.value
+		statsCache.Unlock()
+		return value
+	}
+	generation := keyGeneration(key)
+	statsCache.Unlock()
+	value := compute()
+	statsCache.Lock()
+	if keyGeneration(key) == generation {
+		statsCache.entries[key] = &cacheEntry{value: value, compute: compute}
+	}
+	statsCache.Unlock()
+	return value
+}
+
+//invalidate marks every cached result whose key starts with one of the prefixes as out of date.
+//Without a background refresher, or if it is backed up, out of date results are dropped so the next view recalculates them
+func invalidate(prefixes ...string) {
+	statsCache.Lock()
+	defer statsCache.Unlock()
+	for _, prefix := range prefixes {
+		statsCache.generations[prefix]++
+	}
+	for key, entry := range statsCache.entries {
+		for _, prefix := range prefixes {
+			if !strings.HasPrefix(key, prefix) {
+				continue
+			}
+			if statsCache.refresh == nil {
+				delete(statsCache.entries, key)
+				break
+			}
+			if entry.stale {
+				break
+			}
+			select {
+			case statsCache.refresh <- key:
+				entry.stale = true
+			default:
+				delete(statsCache.entries, key)
+			}
+			break
+		}
+	}
+}
+
+//InvalidateResults marks every cached result which depends on a competitor's scouted results for a match as out of date
+func InvalidateResults(eventid, matchid string, teamNum int) {
+	invalidate(teamKey(eventid, teamNum), matchKey(matchid), eventKey(eventid, ""))
+}
+
+//InvalidateEvent marks every cached result for an event as out of date, for when its schedule, its teams or their prior ratings change
+func InvalidateEvent(eventid string) {
+	invalidate(fmt.Sprintf("team/%s/", eventid), eventKey(eventid, ""))
+}
+
+//InvalidateMatch marks a match's cached summary, and every cached result for its event which depends on who played in it, as out of date
+func InvalidateMatch(eventid, matchid string) {
+	invalidate(matchKey(matchid), eventKey(eventid, ""))
+}
+
+func teamKey(eventid string, teamNum int) string {
+	return fmt.Sprintf("team/%s/%v/", eventid, teamNum)
+}
+
+func matchKey(matchid string) string {
+	return fmt.Sprintf("match/%s/", matchid)
+}
+
+func eventKey(eventid, name string) string {
+	return fmt.Sprintf("event/%s/%s", eventid, name)
+}
+
+//CachedTeamSummary gets everything the data pages show about a team at an event
+func CachedTeamSummary(teamNum int, eventid string) TeamSummary {
+	return cached(teamKey(eventid, teamNum), func() interface{} {
+		summary := TeamSummary{Stats: TeamScoreStats(teamNum, eventid), Breakdowns: TeamBreakdownStats(teamNum, eventid), Reliability: TeamReliability(teamNum, eventid), Prior: TeamPrior(teamNum, eventid)}
+		live := []int{TeamOverall(teamNum, eventid), TeamAuto(teamNum, eventid), TeamShooting(teamNum, eventid), TeamColorWheel(teamNum, eventid), TeamClimbing(teamNum, eventid), TeamFoul(teamNum, eventid)}
+		blended := blendPriorScores(live, summary.Reliability.Matches, summary.Prior)
+		summary.Overall, summary.Auto, summary.Shooting, summary.ColorWheel, summary.Climbing, summary.Fouls = blended[0], blended[1], blended[2], blended[3], blended[4], blended[5]
+		return summary
+	}).(TeamSummary)
+}
+
+//CachedMatchData gets a summary of match scores for the red and blue alliances. See GetMatchData
+func CachedMatchData(matchid string) (MatchResults, error) {
+	type matchData struct {
+		results MatchResults
+		err     error
+	}
+	data := cached(matchKey(matchid), func() interface{} {
+		results, err := GetMatchData(matchid)
+		return matchData{results, err}
+	}).(matchData)
+	return data.results, data.err
+}
+
+//CachedTeamScores gets every team's scores at an event. See GetTeamScores. The scores are copied, so they may be sorted freely
+func CachedTeamScores(eventid string) [][]int {
+	scores := cached(eventKey(eventid, "scores"), func() interface{} {
+		return GetTeamScores(eventid)
+	}).([][]int)
+	copied := make([][]int, len(scores))
+	for ind, score := range scores {
+		copied[ind] = append([]int{}, score...)
+	}
+	return copied
+}
+
+//CachedDefenseRatings gets how every team at an event has played defense. See DefenseRatings
+func CachedDefenseRatings(eventid string) map[int]Defense {
+	return cached(eventKey(eventid, "defense"), func() interface{} {
+		return DefenseRatings(eventid)
+	}).(map[int]Defense)
+}
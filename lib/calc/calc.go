@@ -4,8 +4,11 @@ Package calc provides functions for calculating various game statistics.
 package calc
 
 import (
+	"encoding/json"
 	"errors"
+	"fmt"
 	"math"
+	"math/rand"
 	"sort"
 
 	"EPIC-Scouting/lib/db"
@@ -47,24 +50,50 @@ type MatchResults struct {
 	BlueBalanced        bool
 	RedClimbPoints      int
 	BlueClimbPoints     int
+	RedFoulPoints       int // Points awarded to red for blue's fouls.
+	BlueFoulPoints      int // Points awarded to blue for red's fouls.
 }
 
 /*
-GetTeamScores gets all team breakdown scores from the active event in a campaign
+Disagreement describes a robot in a match whose scouts submitted conflicting data
 */
-func GetTeamScores(campaignid string) [][]int {
+type Disagreement struct {
+	MatchID     string
+	MatchNum    int
+	Team        int
+	Fields      []string       // Names of the fields the scouts disagree on.
+	Submissions []db.MatchData // Every scout's submission, including excluded ones.
+}
+
+/*
+DisagreementTolerance is how far apart two scouts' counts of the same thing may be before they are reported as disagreeing
+*/
+var DisagreementTolerance = 2
+
+/*
+GetTeamScores gets all team breakdown scores at an event
+*/
+func GetTeamScores(eventid string) [][]int {
 	scores := make([][]int, 0)
 	teamData := make(map[int][]db.MatchData, 0)
-	data, _ := db.GetCurrentEventResults(campaignid)
-	for _, match := range *data {
+	data, _ := eventResults(eventid)
+	for _, match := range data {
 		_, ok := teamData[match.Team]
 		if !ok {
 			teamData[match.Team] = make([]db.MatchData, 0)
 		}
 		teamData[match.Team] = append(teamData[match.Team], match)
 	}
+	priors := teamPriors(eventid)
 	for team, matches := range teamData {
-		scores = append(scores, []int{team, Overall(matches), Auto(matches), Shooting(matches), ColorWheel(matches), Climbing(matches), Foul(matches)})
+		live := []int{Overall(matches), Auto(matches), Shooting(matches), ColorWheel(matches), Climbing(matches), Foul(matches)}
+		scores = append(scores, append([]int{team}, blendPriorScores(live, scoutedMatches(matches), priors[team])...))
+	}
+	//teams nobody has scouted yet are rated on their priors alone
+	for team, prior := range priors {
+		if _, ok := teamData[team]; !ok {
+			scores = append(scores, append([]int{team}, blendPriorScores(make([]int, 6), 0, prior)...))
+		}
 	}
 	return scores
 }
@@ -90,13 +119,15 @@ func GetMatchData(matchid string) (MatchResults, error) {
 		}
 		scores = append(scores, participantScores)
 	}
+	if ShareScoringGaps {
+		scores[0], scores[1] = shareMatchGaps(matchid, scores[0], scores[1])
+	}
 	results, err = deriveMatchScores(scores[0], scores[1])
 	return results, err
 }
 
 func deriveMatchScores(red, blue []db.MatchData) (MatchResults, error) {
 	var summary MatchResults
-	var count, redPoints, bluePoints, redRP, blueRP int
 	//TODO this is only commented for testing
 	if len(red) > 0 {
 		summary.MatchNum = red[0].MatchNum
@@ -111,6 +142,13 @@ func deriveMatchScores(red, blue []db.MatchData) (MatchResults, error) {
 	} else {
 		return summary, errors.New("Unable to summarize match: no data provided for one or more alliances")
 	}
+	scoreAlliances(&summary, red, blue)
+	return summary, nil
+}
+
+//scoreAlliances works out each alliance's points and ranking points from its robots' data
+func scoreAlliances(summary *MatchResults, red, blue []db.MatchData) {
+	var count, redPoints, bluePoints, redRP, blueRP int
 	for _, teamdata := range red {
 		if teamdata.AutoLineCross {
 			count++
@@ -290,11 +328,13 @@ func deriveMatchScores(red, blue []db.MatchData) (MatchResults, error) {
 	for _, teamdata := range blue {
 		count += teamdata.Fouls*3 + teamdata.TechFouls*15
 	}
+	summary.RedFoulPoints = count
 	redPoints += count
 	count = 0
 	for _, teamdata := range red {
 		count += teamdata.Fouls*3 + teamdata.TechFouls*15
 	}
+	summary.BlueFoulPoints = count
 	bluePoints += count
 	summary.RedPoints = redPoints
 	summary.BluePoints = bluePoints
@@ -307,7 +347,6 @@ func deriveMatchScores(red, blue []db.MatchData) (MatchResults, error) {
 	}
 	summary.RedRankingPoints = redRP
 	summary.BlueRankingPoints = blueRP
-	return summary, nil
 }
 
 //RawTeamEventData gets a team's raw statistics for an event - best for putting on spreadsheets for raw comparison/printout
@@ -319,23 +358,26 @@ Relative category scores calculate a robot's score compared to the best preforme
 //TODO: make an external reference to the weight of each element on the composite scores
 
 //TeamOverall gets a teams overall score based off a weight table yet to be implemented
-func TeamOverall(teamNum int, campaignid string) int {
-	auto := TeamAuto(teamNum, campaignid)
-	shooting := TeamShooting(teamNum, campaignid)
-	climbing := TeamClimbing(teamNum, campaignid)
-	colorWheel := TeamColorWheel(teamNum, campaignid)
-	foul := TeamFoul(teamNum, campaignid)
+func TeamOverall(teamNum int, eventid string) int {
+	auto := TeamAuto(teamNum, eventid)
+	shooting := TeamShooting(teamNum, eventid)
+	climbing := TeamClimbing(teamNum, eventid)
+	colorWheel := TeamColorWheel(teamNum, eventid)
+	foul := TeamFoul(teamNum, eventid)
 	overall := auto + shooting + climbing + colorWheel - foul
+	if ReliabilityWeight > 0 {
+		overall = weighReliability(overall, TeamReliability(teamNum, eventid))
+	}
 	return overall
 }
 
 //TeamAuto gets a team's autonomous rating
-func TeamAuto(teamNum int, campaignid string) int {
+func TeamAuto(teamNum int, eventid string) int {
 	teamID := db.GetCompetitorID(teamNum)
 	if teamID == "" {
 		return 0
 	}
-	breakdown := TeamAutoBreakdown(teamNum, campaignid)
+	breakdown := TeamAutoBreakdown(teamNum, eventid)
 	weights := []int{5, 4, 2, 1, 1, 1, 1}
 	score := 0
 	for ind, weight := range weights {
@@ -345,12 +387,12 @@ func TeamAuto(teamNum int, campaignid string) int {
 }
 
 //TeamShooting gets a team's overall shooting score
-func TeamShooting(teamNum int, campaignid string) int {
+func TeamShooting(teamNum int, eventid string) int {
 	teamID := db.GetCompetitorID(teamNum)
 	if teamID == "" {
 		return 0
 	}
-	breakdown := TeamShootingBreakdown(teamNum, campaignid)
+	breakdown := TeamShootingBreakdown(teamNum, eventid)
 	weights := []int{1, 2, 3, 5, 3, 2, 1}
 	score := 0
 	for ind, weight := range weights {
@@ -360,12 +402,12 @@ func TeamShooting(teamNum int, campaignid string) int {
 }
 
 //TeamClimbing gets a team's score for climbing
-func TeamClimbing(teamNum int, campaignid string) int {
+func TeamClimbing(teamNum int, eventid string) int {
 	teamID := db.GetCompetitorID(teamNum)
 	if teamID == "" {
 		return 0
 	}
-	breakdown := TeamClimbingBreakdown(teamNum, campaignid)
+	breakdown := TeamClimbingBreakdown(teamNum, eventid)
 	weights := []int{2, 1, 1}
 	score := 0
 	for ind, weight := range weights {
@@ -375,12 +417,12 @@ func TeamClimbing(teamNum int, campaignid string) int {
 }
 
 //TeamColorWheel gets how good a team is at manipulating the color wheel
-func TeamColorWheel(teamNum int, campaignid string) int {
+func TeamColorWheel(teamNum int, eventid string) int {
 	teamID := db.GetCompetitorID(teamNum)
 	if teamID == "" {
 		return 0
 	}
-	breakdown := TeamColorWheelBreakdown(teamNum, campaignid)
+	breakdown := TeamColorWheelBreakdown(teamNum, eventid)
 	weights := []int{1, 1}
 	score := 0
 	for ind, weight := range weights {
@@ -390,12 +432,12 @@ func TeamColorWheel(teamNum int, campaignid string) int {
 }
 
 //TeamFoul gets how many penalties a team accrues. Extra weight to yellow cards and tech fouls
-func TeamFoul(teamNum int, campaignid string) int {
+func TeamFoul(teamNum int, eventid string) int {
 	teamID := db.GetCompetitorID(teamNum)
 	if teamID == "" {
 		return 0
 	}
-	breakdown := TeamFoulBreakdown(teamNum, campaignid)
+	breakdown := TeamFoulBreakdown(teamNum, eventid)
 	weights := []int{1, 3, 2, 2}
 	score := 0
 	for ind, weight := range weights {
@@ -419,16 +461,15 @@ They are also what the above functions use to get their data*/
 
 //TeamAutoBreakdown gets a team's ability to cross the auto line, amount of balls scored in auto, auto accuracy, and ammount of points scored in auto
 //TODO: Finish this
-func TeamAutoBreakdown(teamNum int, campaignID string) []int {
+func TeamAutoBreakdown(teamNum int, eventID string) []int {
 	breakdown := make([]int, 8)
 	//matches is a list of the results struct
-	//TODO: Get eventid from active event on the given campaignid
-	matches, _ := db.GetTeamResults(teamNum, campaignID)
-	if len(*matches) == 0 {
+	matches := TeamResults(teamNum, eventID)
+	if len(matches) == 0 {
 		return breakdown
 	}
 	//totals the scores the team has accumulated over the matches
-	for _, match := range *matches {
+	for _, match := range matches {
 		if match.AutoLineCross {
 			breakdown[0]++
 		}
@@ -444,22 +485,21 @@ func TeamAutoBreakdown(teamNum int, campaignID string) []int {
 		breakdown[7] += breakdown[0]*15 + match.AutoBackBalls*6 + match.AutoHighBalls*4 + match.AutoLowBalls*2
 	}
 	for ind, val := range breakdown {
-		breakdown[ind] = int(math.Round(float64(val) / float64(len(*matches))))
+		breakdown[ind] = int(math.Round(float64(val) / float64(len(matches))))
 	}
 	return breakdown
 }
 
 //TeamShootingBreakdown gets a team's teleop shooting rate, shooting accuracy, ball score rate, and point score rate
-func TeamShootingBreakdown(teamNum int, campaignid string) []int {
+func TeamShootingBreakdown(teamNum int, eventid string) []int {
 	breakdown := make([]int, 7)
 	//matches is a list of the results struct
-	//TODO: Get eventid from active event on the given campaignid
-	matches, _ := db.GetTeamResults(teamNum, campaignid)
-	if len(*matches) == 0 {
+	matches := TeamResults(teamNum, eventid)
+	if len(matches) == 0 {
 		return breakdown
 	}
 	//totals the scores the team has accumulated over the matches
-	for _, match := range *matches {
+	for _, match := range matches {
 		breakdown[0] += match.ShotQuantity
 		breakdown[1] += match.LowFuel
 		breakdown[2] += match.HighFuel
@@ -472,22 +512,21 @@ func TeamShootingBreakdown(teamNum int, campaignid string) []int {
 		breakdown[6] += match.LowFuel*1 + match.HighFuel*2 + match.BackFuel*3
 	}
 	for ind, val := range breakdown {
-		breakdown[ind] = int(math.Round(float64(val) / float64(len(*matches))))
+		breakdown[ind] = int(math.Round(float64(val) / float64(len(matches))))
 	}
 	return breakdown
 }
 
 //TeamClimbingBreakdown gets a team's average climbing speed, ability to balance the bar, and average points scored for climbing
-func TeamClimbingBreakdown(teamNum int, campaignID string) []int {
+func TeamClimbingBreakdown(teamNum int, eventID string) []int {
 	breakdown := make([]int, 3)
 	//matches is a list of the results struct
-	//TODO: Get eventid from active event on the given campaignid
-	matches, _ := db.GetTeamResults(teamNum, campaignID)
-	if len(*matches) == 0 {
+	matches := TeamResults(teamNum, eventID)
+	if len(matches) == 0 {
 		return breakdown
 	}
 	//totals the scores the team has accumulated over the matches
-	for _, match := range *matches {
+	for _, match := range matches {
 		if match.Climbed == "climbed" {
 			breakdown[0] += 2
 		} else if match.Climbed == "platform" {
@@ -501,42 +540,40 @@ func TeamClimbingBreakdown(teamNum int, campaignID string) []int {
 		}
 	}
 	for ind, val := range breakdown {
-		breakdown[ind] = int(math.Round(float64(val) / float64(len(*matches))))
+		breakdown[ind] = int(math.Round(float64(val) / float64(len(matches))))
 	}
 	return breakdown
 }
 
 //TeamColorWheelBreakdown gets how quickly a team can do stage 1 and 2 of the color wheel, along with whether they can do it at all
-func TeamColorWheelBreakdown(teamNum int, campaignID string) []int {
+func TeamColorWheelBreakdown(teamNum int, eventID string) []int {
 	breakdown := make([]int, 2)
 	//matches is a list of the results struct
-	//TODO: Get eventid from active event on the given campaignid
-	matches, _ := db.GetTeamResults(teamNum, campaignID)
-	if len(*matches) == 0 {
+	matches := TeamResults(teamNum, eventID)
+	if len(matches) == 0 {
 		return breakdown
 	}
 	//totals the scores the team has accumulated over the matches
-	for _, match := range *matches {
+	for _, match := range matches {
 		breakdown[0] += match.StageOneTime
 		breakdown[1] += match.StageTwoTime
 	}
 	for ind, val := range breakdown {
-		breakdown[ind] = int(math.Round(float64(val) / float64(len(*matches))))
+		breakdown[ind] = int(math.Round(float64(val) / float64(len(matches))))
 	}
 	return breakdown
 }
 
 //TeamFoulBreakdown gets how many times a team has recieved regular fouls, tech fouls, and yellow cards, along with the total amount of points lost by them to fouls
-func TeamFoulBreakdown(teamNum int, campaignID string) []int {
+func TeamFoulBreakdown(teamNum int, eventID string) []int {
 	breakdown := make([]int, 4)
 	//matches is a list of the results struct
-	//TODO: Get eventid from active event on the given campaignid
-	matches, _ := db.GetTeamResults(teamNum, campaignID)
-	if len(*matches) == 0 {
+	matches := TeamResults(teamNum, eventID)
+	if len(matches) == 0 {
 		return breakdown
 	}
 	//totals the scores the team has accumulated over the matches
-	for _, match := range *matches {
+	for _, match := range matches {
 		breakdown[0] += match.Fouls
 		breakdown[1] += match.TechFouls
 		breakdown[2] += match.Fouls*3 + match.TechFouls*15
@@ -548,7 +585,7 @@ func TeamFoulBreakdown(teamNum int, campaignID string) []int {
 		}
 	}
 	for ind, val := range breakdown {
-		breakdown[ind] = int(math.Round(float64(val) / float64(len(*matches))))
+		breakdown[ind] = int(math.Round(float64(val) / float64(len(matches))))
 	}
 	return breakdown
 }
@@ -564,6 +601,9 @@ func Overall(matches []db.MatchData) int {
 	colorWheel := ColorWheel(matches)
 	foul := Foul(matches)
 	overall := auto + shooting + climbing + colorWheel - foul
+	if ReliabilityWeight > 0 {
+		overall = weighReliability(overall, MatchReliability(matches))
+	}
 	return overall
 }
 
@@ -750,10 +790,963 @@ func FoulBreakdown(matches []db.MatchData) []int {
 
 //Team Overall Scoring and Ranking functions give teams conglomerate scores such as OPR, DPR, and overall ranking
 
+//Certainty Functions measure how much a team's scores can be trusted given how many matches back them
+
+/*
+Stat summarizes one of a team's metrics across the matches it has been scouted in
+*/
+type Stat struct {
+	Mean    float64
+	StdDev  float64 // Sample standard deviation. Zero with fewer than two samples.
+	Samples int
+	Low     float64 // Lower bound of the 95% confidence interval of the mean. -Inf with fewer than two samples.
+	High    float64 // Upper bound of the 95% confidence interval of the mean. +Inf with fewer than two samples.
+}
+
+/*
+BreakdownNames names each element of the breakdowns, in order
+*/
+var BreakdownNames = map[string][]string{
+	"Auto":       {"Auto Line Crosses", "Back Balls", "High Balls", "Low Balls", "Shots", "Pickups", "Accuracy", "Points"},
+	"Shooting":   {"Shots", "Low Fuel", "High Fuel", "Back Fuel", "Accuracy", "Fuel Scored", "Points"},
+	"Climbing":   {"Climb Level", "Climb Speed", "Balanced"},
+	"ColorWheel": {"Stage One Time", "Stage Two Time"},
+	"Fouls":      {"Fouls", "Tech Fouls", "Foul Points", "Cards"},
+}
+
+//String formats a stat as its mean plus or minus its confidence interval, with its sample count
+func (stat Stat) String() string {
+	if stat.Samples < 2 {
+		return fmt.Sprintf("%.1f (n=%v)", stat.Mean, stat.Samples)
+	}
+	return fmt.Sprintf("%.1f ± %.1f (n=%v)", stat.Mean, stat.High-stat.Mean, stat.Samples)
+}
+
+//MarshalJSON writes a stat as JSON. Unbounded confidence intervals are written as null, since JSON has no infinity
+func (stat Stat) MarshalJSON() ([]byte, error) {
+	bound := func(val float64) *float64 {
+		if math.IsInf(val, 0) {
+			return nil
+		}
+		return &val
+	}
+	return json.Marshal(struct {
+		Mean    float64
+		StdDev  float64
+		Samples int
+		Low     *float64
+		High    *float64
+	}{stat.Mean, stat.StdDev, stat.Samples, bound(stat.Low), bound(stat.High)})
+}
+
+//NewStat summarizes a list of per-match values
+func NewStat(values []float64) Stat {
+	stat := Stat{Samples: len(values), Low: math.Inf(-1), High: math.Inf(1)}
+	if len(values) == 0 {
+		return stat
+	}
+	stat.Mean = mean(values)
+	if len(values) < 2 {
+		return stat
+	}
+	//standardDeviation divides by n, so correct it to the sample standard deviation
+	stat.StdDev = standardDeviation(values) * math.Sqrt(float64(len(values))/float64(len(values)-1))
+	margin := tCritical95(len(values)-1) * stat.StdDev / math.Sqrt(float64(len(values)))
+	stat.Low = stat.Mean - margin
+	stat.High = stat.Mean + margin
+	return stat
+}
+
+//tCritical95 gets the two-tailed 95% critical value of Student's t-distribution, which widens confidence intervals when there are only a few samples
+func tCritical95(degreesOfFreedom int) float64 {
+	table := []float64{12.706, 4.303, 3.182, 2.776, 2.571, 2.447, 2.365, 2.306, 2.262, 2.228, 2.201, 2.179, 2.160, 2.145, 2.131, 2.120, 2.110, 2.101, 2.093, 2.086, 2.080, 2.074, 2.069, 2.064, 2.060, 2.056, 2.052, 2.048, 2.045, 2.042}
+	if degreesOfFreedom < 1 {
+		return math.Inf(1)
+	}
+	if degreesOfFreedom <= len(table) {
+		return table[degreesOfFreedom-1]
+	}
+	return 1.96
+}
+
+//TeamResults gets every submission for a team at an event with supervisor rulings applied. See ApplyRulings
+func TeamResults(teamNum int, eventid string) []db.MatchData {
+	matches, err := db.GetTeamResults(teamNum, eventid)
+	if err != nil {
+		return make([]db.MatchData, 0)
+	}
+	return ApplyRulings(*matches)
+}
+
+//eventResults gets every submission at an event with supervisor rulings applied. See ApplyRulings
+func eventResults(eventid string) ([]db.MatchData, error) {
+	data, err := db.GetEventResults(eventid)
+	if err != nil {
+		return nil, err
+	}
+	return ApplyRulings(*data), nil
+}
+
+//teamMatchList gets a team's resolved data for each match it was scouted in at an event
+func teamMatchList(teamNum int, eventid string) []db.MatchData {
+	return ResolveMatchList(TeamResults(teamNum, eventid))
+}
+
+//Scorers maps the name of each score to the function which calculates it
+var Scorers = map[string]func([]db.MatchData) int{"Overall": Overall, "Auto": Auto, "Shooting": Shooting, "ColorWheel": ColorWheel, "Climbing": Climbing, "Fouls": Foul}
+
+//TeamScoreStats gets a team's Overall, Auto, Shooting, ColorWheel, Climbing, and Fouls scores with how much they vary from match to match
+func TeamScoreStats(teamNum int, eventid string) map[string]Stat {
+	return ScoreStats(teamMatchList(teamNum, eventid))
+}
+
+//ScoreStats gets the Overall, Auto, Shooting, ColorWheel, Climbing, and Fouls scores for a list of resolved matches with how much they vary from match to match
+func ScoreStats(matches []db.MatchData) map[string]Stat {
+	stats := make(map[string]Stat)
+	for name, scorer := range Scorers {
+		values := make([]float64, len(matches))
+		for ind, match := range matches {
+			values[ind] = float64(scorer([]db.MatchData{match}))
+		}
+		stats[name] = NewStat(values)
+	}
+	return stats
+}
+
+//TeamBreakdownStats gets each of a team's breakdowns with how much every element varies from match to match. Elements are named by BreakdownNames
+func TeamBreakdownStats(teamNum int, eventid string) map[string][]Stat {
+	matches := teamMatchList(teamNum, eventid)
+	breakdowns := map[string]func([]db.MatchData) []int{"Auto": AutoBreakdown, "Shooting": ShootingBreakdown, "Climbing": ClimbingBreakdown, "ColorWheel": ColorWheelBreakdown, "Fouls": FoulBreakdown}
+	stats := make(map[string][]Stat)
+	for name, breakdown := range breakdowns {
+		values := make([][]float64, len(BreakdownNames[name]))
+		for _, match := range matches {
+			for ind, val := range breakdown([]db.MatchData{match}) {
+				values[ind] = append(values[ind], float64(val))
+			}
+		}
+		for _, elementValues := range values {
+			stats[name] = append(stats[name], NewStat(elementValues))
+		}
+	}
+	return stats
+}
+
+//Reliability Functions measure how consistently a robot delivers what it sets out to do
+
+/*
+Reliability summarizes how often a team's robot succeeds and fails across its matches. Rates are between 0 and 1
+*/
+type Reliability struct {
+	Matches       int
+	ClimbAttempts int
+	ClimbRate     float64 // Climbs achieved out of climbs attempted. 1 if the robot never tried to climb.
+	AutoLineRate  float64
+	DisabledRate  float64
+	TippedRate    float64
+	NoShowRate    float64
+	Score         float64 // From 0 to 100. The average of the climb and auto line rates and the rates of showing up, staying enabled, and staying upright.
+}
+
+/*
+ReliabilityWeight is how much reliability counts toward a team's overall rating, from 0 to 1. At 0 reliability is ignored; at 1 a robot's overall rating is scaled by its reliability score
+*/
+var ReliabilityWeight = 0.0
+
+//TeamReliability gets how consistently a team delivered in each match it was scouted in at an event
+func TeamReliability(teamNum int, eventid string) Reliability {
+	return MatchReliability(teamMatchList(teamNum, eventid))
+}
+
+//MatchReliability gets how consistently a robot delivered across a list of matches
+func MatchReliability(matches []db.MatchData) Reliability {
+	reliability := Reliability{Matches: len(matches), ClimbRate: 1}
+	if len(matches) == 0 {
+		return reliability
+	}
+	var climbs, autoLines, disabled, tipped, noShows int
+	for _, match := range matches {
+		//older scouting forms could not record attempts, so a finished climb counts as attempted
+		if match.ClimbAttempted || match.Climbed == "climbed" {
+			reliability.ClimbAttempts++
+			if match.Climbed == "climbed" {
+				climbs++
+			}
+		}
+		if match.AutoLineCross {
+			autoLines++
+		}
+		if match.Disabled {
+			disabled++
+		}
+		if match.Tipped {
+			tipped++
+		}
+		if match.NoShow {
+			noShows++
+		}
+	}
+	count := float64(len(matches))
+	if reliability.ClimbAttempts > 0 {
+		reliability.ClimbRate = float64(climbs) / float64(reliability.ClimbAttempts)
+	}
+	reliability.AutoLineRate = float64(autoLines) / count
+	reliability.DisabledRate = float64(disabled) / count
+	reliability.TippedRate = float64(tipped) / count
+	reliability.NoShowRate = float64(noShows) / count
+	reliability.Score = 100 * mean([]float64{reliability.ClimbRate, reliability.AutoLineRate, 1 - reliability.DisabledRate, 1 - reliability.TippedRate, 1 - reliability.NoShowRate})
+	return reliability
+}
+
+//weighReliability scales an overall rating by a reliability score according to ReliabilityWeight
+func weighReliability(overall int, reliability Reliability) int {
+	if reliability.Matches == 0 {
+		return overall
+	}
+	scale := 1 - ReliabilityWeight + ReliabilityWeight*reliability.Score/100
+	return int(math.Round(float64(overall) * scale))
+}
+
+//Prior Functions rate teams from before an event, so they aren't all rated 0 until their first matches are scouted
+
+/*
+PriorMatches is how many scouted matches a team's prior ratings count for at most when they are blended with its ratings from scouting at an event. The priors fade as its matches are scouted. At 0 or below priors are ignored
+*/
+var PriorMatches = 3
+
+//teamPriors gets the prior ratings of every team at an event, by team number
+func teamPriors(eventid string) map[int]db.Prior {
+	if PriorMatches <= 0 {
+		return make(map[int]db.Prior)
+	}
+	priors, err := db.GetPriors(eventid)
+	if err != nil {
+		return make(map[int]db.Prior)
+	}
+	return priors
+}
+
+//TeamPrior gets a team's prior ratings at an event. Matches is 0 if it has none
+func TeamPrior(teamNum int, eventid string) db.Prior {
+	return teamPriors(eventid)[teamNum]
+}
+
+//blendPrior blends a rating from some scouted matches with a prior rating from some earlier matches. The prior counts for as many matches as it is from, up to PriorMatches
+func blendPrior(live, scouted, prior, priorMatches int) int {
+	weight := priorMatches
+	if weight > PriorMatches {
+		weight = PriorMatches
+	}
+	if weight <= 0 {
+		return live
+	}
+	return int(math.Round(float64(prior*weight+live*scouted) / float64(weight+scouted)))
+}
+
+//blendPriorScores blends a team's Overall, Auto, Shooting, ColorWheel, Climbing, and Fouls ratings from scouting with its prior ratings, in that order
+func blendPriorScores(live []int, scouted int, prior db.Prior) []int {
+	priorScores := []int{prior.Overall, prior.Auto, prior.Shooting, prior.ColorWheel, prior.Climbing, prior.Fouls}
+	blended := make([]int, len(live))
+	for ind := range live {
+		blended[ind] = blendPrior(live[ind], scouted, priorScores[ind], prior.Matches)
+	}
+	return blended
+}
+
+//scoutedMatches counts the matches in a list of results, however many scouts each was scouted by
+func scoutedMatches(matches []db.MatchData) int {
+	ids := make(map[string]bool)
+	for _, match := range matches {
+		ids[match.MatchID] = true
+	}
+	return len(ids)
+}
+
+//Defense Functions measure how much a robot holds back the alliances it plays against
+
+/*
+Defense summarizes how a team has played defense at an event
+*/
+type Defense struct {
+	Rating  Stat    // How many fewer Overall points each opponent scored than its own average, summed over the opposing alliance, per match the team defended in. Empty if it never defended.
+	Time    float64 // Average seconds per match spent defending, as recorded by scouts.
+	Quality float64 // Average defense quality, as recorded by scouts. See db.DefenseQualityNames.
+	Matches int     // How many matches scouts recorded the team defending in.
+}
+
+//TeamDefense gets how a team has played defense at an event
+func TeamDefense(teamNum int, eventid string) Defense {
+	return DefenseRatings(eventid)[teamNum]
+}
+
+//DefenseRatings gets how every team at an event has played defense.
+//Each match a team defended in, every scouted opponent's Overall score is compared with its average Overall score in its other matches, so a robot is not credited for holding back an opponent that always scores poorly.
+func DefenseRatings(eventid string) map[int]Defense {
+	defenses := make(map[int]Defense)
+	data, err := eventResults(eventid)
+	if err != nil {
+		return defenses
+	}
+	teamData := make(map[int][]db.MatchData)
+	for _, match := range data {
+		teamData[match.Team] = append(teamData[match.Team], match)
+	}
+	//each team's Overall score in each match, and its total across them
+	scores := make(map[int]map[string]float64)
+	totals := make(map[int]float64)
+	resolvedMatches := make(map[int][]db.MatchData)
+	for team, matches := range teamData {
+		scores[team] = make(map[string]float64)
+		for _, match := range ResolveMatchList(matches) {
+			match.Team = team
+			score := float64(Overall([]db.MatchData{match}))
+			scores[team][match.MatchID] = score
+			totals[team] += score
+			resolvedMatches[team] = append(resolvedMatches[team], match)
+		}
+	}
+	participants := make(map[string][][]int)
+	for team, matches := range resolvedMatches {
+		var defense Defense
+		ratings := make([]float64, 0)
+		for _, match := range matches {
+			defense.Time += float64(match.DefenseTime)
+			defense.Quality += float64(match.DefenseQuality)
+			//a robot is only credited with holding back opponents in matches where it played defense
+			if match.DefenseTime == 0 && match.DefenseQuality == 0 {
+				continue
+			}
+			defense.Matches++
+			_, ok := participants[match.MatchID]
+			if !ok {
+				participants[match.MatchID] = db.GetMatchParticipants(match.MatchID)
+			}
+			opponents := participants[match.MatchID][0]
+			if contains(opponents, team) {
+				opponents = participants[match.MatchID][1]
+			}
+			held := 0.0
+			compared := 0
+			for _, opponent := range opponents {
+				score, ok := scores[opponent][match.MatchID]
+				if !ok || len(scores[opponent]) < 2 {
+					continue
+				}
+				usual := (totals[opponent] - score) / float64(len(scores[opponent])-1)
+				held += usual - score
+				compared++
+			}
+			if compared > 0 {
+				ratings = append(ratings, held)
+			}
+		}
+		defense.Time /= float64(len(matches))
+		defense.Quality /= float64(len(matches))
+		defense.Rating = NewStat(ratings)
+		defenses[team] = defense
+	}
+	return defenses
+}
+
+/*
+Trend is one of a team's scores over the course of an event, with the line of best fit through it
+*/
+type Trend struct {
+	MatchNums []int
+	Scores    []float64
+	Slope     float64 // How much the score changes per match played.
+	Intercept float64 // The fitted score before the team's first match.
+}
+
+//TeamTrends gets how each of a team's scores, named as in Scorers, has changed over the matches it was scouted in at an event
+func TeamTrends(teamNum int, eventid string) map[string]Trend {
+	matches := teamMatchList(teamNum, eventid)
+	sort.Slice(matches, func(i, j int) bool {
+		return matches[i].MatchNum < matches[j].MatchNum
+	})
+	trends := make(map[string]Trend)
+	for name, scorer := range Scorers {
+		trend := Trend{MatchNums: make([]int, len(matches)), Scores: make([]float64, len(matches))}
+		for ind, match := range matches {
+			trend.MatchNums[ind] = match.MatchNum
+			trend.Scores[ind] = float64(scorer([]db.MatchData{match}))
+		}
+		trend.Slope, trend.Intercept = fitLine(trend.Scores)
+		trends[name] = trend
+	}
+	return trends
+}
+
+//fitLine finds the least squares line through a list of values, taking each value's position in the list as its x value
+func fitLine(values []float64) (float64, float64) {
+	if len(values) == 0 {
+		return 0, 0
+	}
+	if len(values) == 1 {
+		return 0, values[0]
+	}
+	var sumX, sumY, sumXY, sumXX float64
+	for ind, val := range values {
+		x := float64(ind + 1)
+		sumX += x
+		sumY += val
+		sumXY += x * val
+		sumXX += x * x
+	}
+	count := float64(len(values))
+	slope := (count*sumXY - sumX*sumY) / (count*sumXX - sumX*sumX)
+	return slope, (sumY - slope*sumX) / count
+}
+
 //TeamOverallEvent gives a team an overall quality score
 
 //RankEventTeams ranks all teams from best to worst based on their overall score
 
+//Strength of Schedule Functions measure how much a team's partners and opponents help or hurt it in qualifications
+
+/*
+ScheduleStrength describes how hard a team's qualification schedule is, judged by its partners' and opponents' overall ratings.
+Strength is the opponents' average rating minus the partners' average rating, so a team with an average schedule scores 0, a harder schedule scores above 0, and an easier one below
+*/
+type ScheduleStrength struct {
+	Team               int
+	Played             int
+	Remaining          int
+	PlayedPartners     float64 // The average overall rating of the team's partners in completed matches.
+	PlayedOpponents    float64
+	PlayedStrength     float64
+	RemainingPartners  float64 // The average overall rating of the team's partners in matches still to be played.
+	RemainingOpponents float64
+	RemainingStrength  float64
+	Verdict            string // "carried" if the completed schedule was easy enough to flatter the team's ranking, "held back" if it was hard enough to hurt it, otherwise empty.
+}
+
+/*
+ScheduleVerdictMargin is how far from 0, in standard deviations of the event's overall ratings, a completed schedule's strength must be for the team to be called carried or held back
+*/
+var ScheduleVerdictMargin = 0.5
+
+//TeamScheduleStrength gets how hard a team's qualification schedule at an event is
+func TeamScheduleStrength(teamNum int, eventid string) ScheduleStrength {
+	strength, ok := ScheduleStrengths(eventid)[teamNum]
+	if !ok {
+		strength.Team = teamNum
+	}
+	return strength
+}
+
+//ScheduleStrengths gets how hard every scheduled team's qualification schedule at an event is.
+//A match is completed once any robot in it has been scouted. Teams with no scouted matches are rated as the event average
+func ScheduleStrengths(eventid string) map[int]ScheduleStrength {
+	strengths := make(map[int]ScheduleStrength)
+	schedule, _ := db.GetEventSchedule(eventid)
+	data, err := eventResults(eventid)
+	if err != nil {
+		return strengths
+	}
+	completed := make(map[string]bool)
+	for _, d := range data {
+		completed[d.MatchID] = true
+	}
+	ratings := make(map[int]float64)
+	ratingList := make([]float64, 0)
+	for _, score := range GetTeamScores(eventid) {
+		ratings[score[0]] = float64(score[1])
+		ratingList = append(ratingList, float64(score[1]))
+	}
+	average := 0.0
+	if len(ratingList) > 0 {
+		average = mean(ratingList)
+	}
+	rating := func(team int) float64 {
+		if val, ok := ratings[team]; ok {
+			return val
+		}
+		return average
+	}
+	type totals struct {
+		matches, partners, opponents int
+		partnerSum, opponentSum      float64
+	}
+	played := make(map[int]*totals)
+	remaining := make(map[int]*totals)
+	for _, match := range schedule {
+		for _, alliances := range [][][]int{{match.Red, match.Blue}, {match.Blue, match.Red}} {
+			for _, team := range alliances[0] {
+				record := remaining
+				if completed[match.MatchID] {
+					record = played
+				}
+				if record[team] == nil {
+					record[team] = &totals{}
+				}
+				record[team].matches++
+				for _, partner := range alliances[0] {
+					if partner != team {
+						record[team].partners++
+						record[team].partnerSum += rating(partner)
+					}
+				}
+				for _, opponent := range alliances[1] {
+					record[team].opponents++
+					record[team].opponentSum += rating(opponent)
+				}
+			}
+		}
+	}
+	averages := func(record *totals) (int, float64, float64) {
+		if record == nil {
+			return 0, 0, 0
+		}
+		var partners, opponents float64
+		if record.partners > 0 {
+			partners = record.partnerSum / float64(record.partners)
+		}
+		if record.opponents > 0 {
+			opponents = record.opponentSum / float64(record.opponents)
+		}
+		return record.matches, partners, opponents
+	}
+	margin := ScheduleVerdictMargin * standardDeviation(ratingList)
+	for _, record := range []map[int]*totals{played, remaining} {
+		for team := range record {
+			if _, ok := strengths[team]; ok {
+				continue
+			}
+			strength := ScheduleStrength{Team: team}
+			strength.Played, strength.PlayedPartners, strength.PlayedOpponents = averages(played[team])
+			strength.PlayedStrength = strength.PlayedOpponents - strength.PlayedPartners
+			strength.Remaining, strength.RemainingPartners, strength.RemainingOpponents = averages(remaining[team])
+			strength.RemainingStrength = strength.RemainingOpponents - strength.RemainingPartners
+			if strength.Played > 0 && margin > 0 {
+				if strength.PlayedStrength < -margin {
+					strength.Verdict = "carried"
+				} else if strength.PlayedStrength > margin {
+					strength.Verdict = "held back"
+				}
+			}
+			strengths[team] = strength
+		}
+	}
+	return strengths
+}
+
+//Ranking Projection Functions
+
+/*
+RankProjection is a team's projected final qualification ranking
+*/
+type RankProjection struct {
+	Team          int
+	RankingPoints int       // Ranking points earned in completed matches.
+	Remaining     int       // Scheduled qualification matches the team has left to play.
+	MeanRank      float64   // The team's average rank across simulations.
+	RankChances   []float64 // RankChances[i] is the chance the team finishes in rank i+1.
+	TopEight      float64   // The chance the team finishes in the top 8, and so is likely to be an alliance captain.
+}
+
+/*
+ProjectionSimulations is how many times ProjectRankings simulates the remaining qualification matches by default
+*/
+var ProjectionSimulations = 2000
+
+/*
+MaxProjectionSimulations is the most simulations ProjectRankings runs, however many are asked for
+*/
+const MaxProjectionSimulations = 20000
+
+//ProjectRankings simulates the rest of the qualification schedule at an event and projects each team's final rank.
+//A match is played once any robot in it has been scouted, and its ranking points are counted from whichever of its robots were scouted.
+//Each simulated robot plays like one of its scouted matches, picked at random, and alliances are scored with the same ranking point rules as scouted matches. Robots with no scouted matches play like a random robot from the event.
+//Ties in ranking points are broken by total match points, then at random.
+func ProjectRankings(eventid string, simulations int, seed int64) []RankProjection {
+	projections := make([]RankProjection, 0)
+	schedule, _ := db.GetEventSchedule(eventid)
+	data, err := eventResults(eventid)
+	if err != nil {
+		return projections
+	}
+	teamData := make(map[int][]db.MatchData)
+	completed := make(map[string]bool)
+	for _, d := range data {
+		teamData[d.Team] = append(teamData[d.Team], d)
+		completed[d.MatchID] = true
+	}
+	history := make(map[int][]db.MatchData)
+	pool := make([]db.MatchData, 0)
+	for team, matches := range teamData {
+		history[team] = ResolveMatchList(matches)
+		pool = append(pool, history[team]...)
+	}
+	teams := make([]int, 0)
+	addTeam := func(team int) {
+		if !contains(teams, team) {
+			teams = append(teams, team)
+		}
+	}
+	for team := range teamData {
+		addTeam(team)
+	}
+	//each robot's resolved data in the matches it was scouted in, for matches missing a whole alliance
+	played := make(map[string]map[int]db.MatchData)
+	for team, matches := range history {
+		for _, match := range matches {
+			if played[match.MatchID] == nil {
+				played[match.MatchID] = make(map[int]db.MatchData)
+			}
+			played[match.MatchID][team] = match
+		}
+	}
+	rankingPoints := make(map[int]int)
+	matchPoints := make(map[int]int)
+	remaining := make([]db.ScheduledMatch, 0)
+	remainingCount := make(map[int]int)
+	for _, match := range schedule {
+		for _, team := range append(append([]int{}, match.Red...), match.Blue...) {
+			addTeam(team)
+			if !completed[match.MatchID] {
+				remainingCount[team]++
+			}
+		}
+		if !completed[match.MatchID] {
+			remaining = append(remaining, match)
+			continue
+		}
+		summary, err := CachedMatchData(match.MatchID)
+		if err != nil {
+			//only the robots which were scouted are scored
+			red := make([]db.MatchData, 0, len(match.Red))
+			blue := make([]db.MatchData, 0, len(match.Blue))
+			for _, team := range match.Red {
+				if data, ok := played[match.MatchID][team]; ok {
+					red = append(red, data)
+				}
+			}
+			for _, team := range match.Blue {
+				if data, ok := played[match.MatchID][team]; ok {
+					blue = append(blue, data)
+				}
+			}
+			summary = MatchResults{}
+			scoreAlliances(&summary, red, blue)
+		}
+		for _, team := range match.Red {
+			rankingPoints[team] += summary.RedRankingPoints
+			matchPoints[team] += summary.RedPoints
+		}
+		for _, team := range match.Blue {
+			rankingPoints[team] += summary.BlueRankingPoints
+			matchPoints[team] += summary.BluePoints
+		}
+	}
+	if len(teams) == 0 {
+		return projections
+	}
+	if simulations <= 0 {
+		simulations = ProjectionSimulations
+	}
+	if simulations > MaxProjectionSimulations {
+		simulations = MaxProjectionSimulations
+	}
+	rng := rand.New(rand.NewSource(seed))
+	sample := func(team int) db.MatchData {
+		if len(history[team]) > 0 {
+			return history[team][rng.Intn(len(history[team]))]
+		}
+		if len(pool) > 0 {
+			return pool[rng.Intn(len(pool))]
+		}
+		return db.MatchData{}
+	}
+	rankCounts := make(map[int][]int)
+	for _, team := range teams {
+		rankCounts[team] = make([]int, len(teams))
+	}
+	simRP := make(map[int]int)
+	simPoints := make(map[int]int)
+	tiebreak := make(map[int]float64)
+	order := make([]int, len(teams))
+	for sim := 0; sim < simulations; sim++ {
+		for _, team := range teams {
+			simRP[team] = rankingPoints[team]
+			simPoints[team] = matchPoints[team]
+			tiebreak[team] = rng.Float64()
+		}
+		for _, match := range remaining {
+			red := make([]db.MatchData, 0, len(match.Red))
+			blue := make([]db.MatchData, 0, len(match.Blue))
+			for _, team := range match.Red {
+				red = append(red, sample(team))
+			}
+			for _, team := range match.Blue {
+				blue = append(blue, sample(team))
+			}
+			var summary MatchResults
+			scoreAlliances(&summary, red, blue)
+			for _, team := range match.Red {
+				simRP[team] += summary.RedRankingPoints
+				simPoints[team] += summary.RedPoints
+			}
+			for _, team := range match.Blue {
+				simRP[team] += summary.BlueRankingPoints
+				simPoints[team] += summary.BluePoints
+			}
+		}
+		copy(order, teams)
+		sort.Slice(order, func(i, j int) bool {
+			a, b := order[i], order[j]
+			if simRP[a] != simRP[b] {
+				return simRP[a] > simRP[b]
+			}
+			if simPoints[a] != simPoints[b] {
+				return simPoints[a] > simPoints[b]
+			}
+			return tiebreak[a] > tiebreak[b]
+		})
+		for rank, team := range order {
+			rankCounts[team][rank]++
+		}
+	}
+	for _, team := range teams {
+		projection := RankProjection{Team: team, RankingPoints: rankingPoints[team], Remaining: remainingCount[team], RankChances: make([]float64, len(teams))}
+		for rank, count := range rankCounts[team] {
+			chance := float64(count) / float64(simulations)
+			projection.RankChances[rank] = chance
+			projection.MeanRank += float64(rank+1) * chance
+			if rank < 8 {
+				projection.TopEight += chance
+			}
+		}
+		projections = append(projections, projection)
+	}
+	sort.Slice(projections, func(i, j int) bool {
+		return projections[i].MeanRank < projections[j].MeanRank
+	})
+	return projections
+}
+
+//Reconciliation Functions compare scouted alliance totals with the official score breakdowns
+
+/*
+Reconciliation compares an alliance's scouted points in a match with its official score breakdown
+*/
+type Reconciliation struct {
+	MatchID    string
+	MatchNum   int
+	Alliance   string
+	Scouted    db.OfficialScore // Scouted points, broken down the same way as the official score.
+	Official   db.OfficialScore
+	Categories []string // Names of the categories where scouting is off by more than the threshold.
+}
+
+/*
+ReconcileThreshold is how many points an alliance's scouted total in any category may be off from the official breakdown before the match is flagged
+*/
+var ReconcileThreshold = 10
+
+/*
+ShareScoringGaps sets whether points the scouts missed, according to the official breakdown, are shared out across the alliance's robots when summarizing a match
+*/
+var ShareScoringGaps = false
+
+//ReconcileEvent reconciles every match at an event which has an official score. Only alliances off by more than the threshold are returned unless all is true
+func ReconcileEvent(eventid string, threshold int, all bool) []Reconciliation {
+	reconciliations := make([]Reconciliation, 0)
+	for _, matchid := range db.GetEventMatchIDs(eventid) {
+		matchReconciliations, err := ReconcileMatch(matchid, threshold)
+		if err != nil {
+			continue
+		}
+		for _, reconciliation := range matchReconciliations {
+			if all || len(reconciliation.Categories) > 0 {
+				reconciliations = append(reconciliations, reconciliation)
+			}
+		}
+	}
+	sort.Slice(reconciliations, func(i, j int) bool {
+		if reconciliations[i].MatchNum == reconciliations[j].MatchNum {
+			return reconciliations[i].Alliance > reconciliations[j].Alliance
+		}
+		return reconciliations[i].MatchNum < reconciliations[j].MatchNum
+	})
+	return reconciliations
+}
+
+//ReconcileMatch compares each alliance's scouted points in a match with its official score breakdown
+func ReconcileMatch(matchid string, threshold int) ([]Reconciliation, error) {
+	reconciliations := make([]Reconciliation, 0)
+	official, err := db.GetOfficialScores(matchid)
+	if err != nil {
+		return reconciliations, err
+	}
+	if len(official) == 0 {
+		return reconciliations, errors.New("Unable to reconcile match: no official score")
+	}
+	matchParticipants := db.GetMatchParticipants(matchid)
+	scores := make([][]db.MatchData, 2)
+	for alliance := range matchParticipants {
+		for _, team := range matchParticipants[alliance] {
+			scores[alliance] = append(scores[alliance], ResolveMatchConflicts(team, matchid))
+		}
+	}
+	var summary MatchResults
+	scoreAlliances(&summary, scores[0], scores[1])
+	for _, score := range official {
+		reconciliation := Reconciliation{MatchID: matchid, MatchNum: score.MatchNum, Alliance: score.Alliance, Official: score, Scouted: scoutedScore(summary, score.Alliance)}
+		reconciliation.Scouted.MatchID = matchid
+		reconciliation.Scouted.MatchNum = score.MatchNum
+		reconciliation.Categories = scoreDifferences(reconciliation.Scouted, score, threshold)
+		reconciliations = append(reconciliations, reconciliation)
+	}
+	return reconciliations, nil
+}
+
+//scoutedScore breaks down an alliance's scouted points the same way as an official score
+func scoutedScore(summary MatchResults, alliance string) db.OfficialScore {
+	if alliance == "red" {
+		return db.OfficialScore{Alliance: alliance, AutoPoints: summary.RedAutoPoints, TeleopCellPoints: summary.RedShootingPoints, EndgamePoints: summary.RedClimbPoints, FoulPoints: summary.RedFoulPoints, TotalPoints: summary.RedPoints}
+	}
+	return db.OfficialScore{Alliance: alliance, AutoPoints: summary.BlueAutoPoints, TeleopCellPoints: summary.BlueShootingPoints, EndgamePoints: summary.BlueClimbPoints, FoulPoints: summary.BlueFoulPoints, TotalPoints: summary.BluePoints}
+}
+
+//scoreDifferences lists the categories where two scores are more than the threshold apart
+func scoreDifferences(scouted, official db.OfficialScore, threshold int) []string {
+	categories := make([]string, 0)
+	differences := []struct {
+		name     string
+		scouted  int
+		official int
+	}{
+		{"Auto", scouted.AutoPoints, official.AutoPoints},
+		{"Teleop Cells", scouted.TeleopCellPoints, official.TeleopCellPoints},
+		{"Endgame", scouted.EndgamePoints, official.EndgamePoints},
+		{"Fouls", scouted.FoulPoints, official.FoulPoints},
+		{"Total", scouted.TotalPoints, official.TotalPoints},
+	}
+	for _, difference := range differences {
+		if int(math.Abs(float64(difference.scouted-difference.official))) > threshold {
+			categories = append(categories, difference.name)
+		}
+	}
+	return categories
+}
+
+//shareMatchGaps shares out the auto and teleop points each alliance's scouts missed, according to the official breakdown, across the alliance's robots. Robots are credited in proportion to the points they were scouted scoring, or evenly if none were, as extra high goals
+func shareMatchGaps(matchid string, red, blue []db.MatchData) ([]db.MatchData, []db.MatchData) {
+	official, err := db.GetOfficialScores(matchid)
+	if err != nil || len(official) == 0 {
+		return red, blue
+	}
+	var summary MatchResults
+	scoreAlliances(&summary, red, blue)
+	for _, score := range official {
+		scouted := scoutedScore(summary, score.Alliance)
+		robots := red
+		if score.Alliance == "blue" {
+			robots = blue
+		}
+		robots = append([]db.MatchData{}, robots...)
+		shareGap(robots, score.AutoPoints-scouted.AutoPoints, 4, func(d db.MatchData) int {
+			return 2*d.AutoLowBalls + 4*d.AutoHighBalls + 6*d.AutoBackBalls
+		}, func(d *db.MatchData, balls int) {
+			d.AutoHighBalls += balls
+		})
+		shareGap(robots, score.TeleopCellPoints-scouted.TeleopCellPoints, 2, func(d db.MatchData) int {
+			return d.LowFuel + 2*d.HighFuel + 3*d.BackFuel
+		}, func(d *db.MatchData, balls int) {
+			d.HighFuel += balls
+		})
+		if score.Alliance == "red" {
+			red = robots
+		} else {
+			blue = robots
+		}
+	}
+	return red, blue
+}
+
+//shareGap credits robots with enough balls, worth ballPoints each, to make up a points gap. Gaps where scouts over-counted are left alone
+func shareGap(robots []db.MatchData, gap, ballPoints int, points func(db.MatchData) int, credit func(*db.MatchData, int)) {
+	if gap < ballPoints || len(robots) == 0 {
+		return
+	}
+	balls := gap / ballPoints
+	total := 0
+	for _, robot := range robots {
+		total += points(robot)
+	}
+	given := 0
+	for ind := range robots {
+		share := balls / len(robots)
+		if total > 0 {
+			share = balls * points(robots[ind]) / total
+		}
+		credit(&robots[ind], share)
+		given += share
+	}
+	//rounding leftovers go to the first robot
+	credit(&robots[0], balls-given)
+}
+
+//Alliance Selection Functions
+
+//SeedPickList orders every team at an event for a pick list. First picks are ordered by overall score. Second picks are ordered by their climbing and autonomous scores less their fouls, since a second pick needs to be dependable more than it needs to be flashy
+func SeedPickList(eventid, listType string) []int {
+	scores := GetTeamScores(eventid)
+	rating := func(score []int) int {
+		if listType == db.PickListSecond {
+			return score[5] + score[2] - score[6]
+		}
+		return score[1]
+	}
+	sort.SliceStable(scores, func(i, j int) bool {
+		if rating(scores[i]) == rating(scores[j]) {
+			return scores[i][0] < scores[j][0]
+		}
+		return rating(scores[i]) > rating(scores[j])
+	})
+	teams := make([]int, len(scores))
+	for ind, score := range scores {
+		teams[ind] = score[0]
+	}
+	return teams
+}
+
+//SuggestComplements orders candidate teams by how well they complement our robot. A candidate earns points for each category it scores better than us in, by the amount it makes up the difference, and loses points for its fouls
+func SuggestComplements(ourTeam int, candidates []int, eventid string) []int {
+	teamScores := make(map[int][]int)
+	for _, score := range GetTeamScores(eventid) {
+		teamScores[score[0]] = score
+	}
+	ours, ok := teamScores[ourTeam]
+	if !ok {
+		ours = make([]int, 7)
+	}
+	complement := make(map[int]int)
+	suggestions := make([]int, 0, len(candidates))
+	for _, team := range candidates {
+		if team == ourTeam {
+			continue
+		}
+		theirs, ok := teamScores[team]
+		if !ok {
+			continue
+		}
+		//auto, shooting, color wheel, and climbing
+		for category := 2; category <= 5; category++ {
+			if theirs[category] > ours[category] {
+				complement[team] += theirs[category] - ours[category]
+			}
+		}
+		complement[team] -= theirs[6]
+		suggestions = append(suggestions, team)
+	}
+	sort.SliceStable(suggestions, func(i, j int) bool {
+		return complement[suggestions[i]] > complement[suggestions[j]]
+	})
+	return suggestions
+}
+
 //Match Filtering Functions
 
 //filterTeamMatchesBefore returns all match data from a team at an event before a given match number. Includes the given match number
@@ -802,12 +1795,12 @@ ResolveMatchConflicts takes multiple scouter's data that may contradict and comb
 func ResolveMatchConflicts(teamNum int, matchid string) db.MatchData {
 	var resolved db.MatchData
 	data, _ := db.GetTeamMatchResults(teamNum, matchid)
-	resolved = ResolveDataConflicts(*data)
+	resolved = ResolveDataConflicts(ApplyRulings(*data))
 	return resolved
 }
 
 /*
-ResolveDataConflicts resolves discrepencies between scouting data
+ResolveDataConflicts resolves discrepencies between scouting data. Supervisor rulings should already have been applied to it, as they are where results are loaded. See ApplyRulings
 */
 func ResolveDataConflicts(data []db.MatchData) db.MatchData {
 	var resolved db.MatchData
@@ -828,10 +1821,16 @@ func ResolveDataConflicts(data []db.MatchData) db.MatchData {
 	foulsList := make([]int, len(data))
 	techFoulsList := make([]int, len(data))
 	climbTimeList := make([]int, len(data))
+	defenseTimeList := make([]int, len(data))
+	defenseQualityList := make([]int, len(data))
 	autoLineCrossList := make([]bool, len(data))
 	stageOneCompleteList := make([]bool, len(data))
 	stageTwoCompleteList := make([]bool, len(data))
 	balancedList := make([]bool, len(data))
+	climbAttemptedList := make([]bool, len(data))
+	disabledList := make([]bool, len(data))
+	tippedList := make([]bool, len(data))
+	noShowList := make([]bool, len(data))
 	cardList := make([]string, len(data))
 	climbedList := make([]string, len(data))
 	for ind, d := range data {
@@ -849,10 +1848,16 @@ func ResolveDataConflicts(data []db.MatchData) db.MatchData {
 		foulsList[ind] = d.Fouls
 		techFoulsList[ind] = d.TechFouls
 		climbTimeList[ind] = d.ClimbTime
+		defenseTimeList[ind] = d.DefenseTime
+		defenseQualityList[ind] = d.DefenseQuality
 		autoLineCrossList[ind] = d.AutoLineCross
 		stageOneCompleteList[ind] = d.StageOneComplete
 		stageTwoCompleteList[ind] = d.StageTwoComplete
 		balancedList[ind] = d.Balanced
+		climbAttemptedList[ind] = d.ClimbAttempted
+		disabledList[ind] = d.Disabled
+		tippedList[ind] = d.Tipped
+		noShowList[ind] = d.NoShow
 		cardList[ind] = d.Card
 		climbedList[ind] = d.Climbed
 	}
@@ -872,10 +1877,16 @@ func ResolveDataConflicts(data []db.MatchData) db.MatchData {
 	resolved.Fouls = resolveInt(foulsList)
 	resolved.TechFouls = resolveInt(techFoulsList)
 	resolved.ClimbTime = resolveIntMean(climbTimeList)
+	resolved.DefenseTime = resolveIntMean(defenseTimeList)
+	resolved.DefenseQuality = resolveInt(defenseQualityList)
 	resolved.AutoLineCross = resolveBool(autoLineCrossList)
 	resolved.StageOneComplete = resolveBool(stageOneCompleteList)
 	resolved.StageTwoComplete = resolveBool(stageTwoCompleteList)
 	resolved.Balanced = resolveBool(balancedList)
+	resolved.ClimbAttempted = resolveBool(climbAttemptedList)
+	resolved.Disabled = resolveBool(disabledList)
+	resolved.Tipped = resolveBool(tippedList)
+	resolved.NoShow = resolveBool(noShowList)
 	resolved.Card = resolveString(cardList)
 	resolved.Climbed = resolveString(climbedList)
 	resolved.Team = data[0].Team
@@ -968,6 +1979,110 @@ func contains(arr []int, val int) bool {
 
 //IsUnanimous checks if data has significant disagreements - this usually means non-identical pieces of data unless we had something dealing in decimals/seconds
 
+/*
+ApplyRulings applies supervisor rulings to scouter data. Excluded entries are dropped, and if any entry for a robot in a match is authoritative it is kept in place of the others
+*/
+func ApplyRulings(data []db.MatchData) []db.MatchData {
+	authoritative := make(map[string]db.MatchData)
+	for _, d := range data {
+		if d.Ruling == db.RulingAuthoritative {
+			authoritative[fmt.Sprintf("%s/%v", d.MatchID, d.Team)] = d
+		}
+	}
+	ruled := make([]db.MatchData, 0, len(data))
+	for _, d := range data {
+		if d.Ruling == db.RulingExcluded {
+			continue
+		}
+		chosen, ok := authoritative[fmt.Sprintf("%s/%v", d.MatchID, d.Team)]
+		if ok && chosen.ScoutID != d.ScoutID {
+			continue
+		}
+		ruled = append(ruled, d)
+	}
+	return ruled
+}
+
+/*
+FindDisagreements finds every robot in every match of an event whose scouts disagree.
+Counts disagree if they differ by more than the tolerance, and any difference in yes/no or multiple choice answers is a disagreement.
+*/
+func FindDisagreements(eventid string, tolerance int) []Disagreement {
+	disagreements := make([]Disagreement, 0)
+	robotMatches := make(map[string][]db.MatchData)
+	data, err := db.GetEventResults(eventid)
+	if err != nil {
+		return disagreements
+	}
+	for _, d := range *data {
+		key := fmt.Sprintf("%s/%v", d.MatchID, d.Team)
+		robotMatches[key] = append(robotMatches[key], d)
+	}
+	for _, submissions := range robotMatches {
+		if len(submissions) < 2 {
+			continue
+		}
+		fields := disagreeingFields(submissions, tolerance)
+		if len(fields) == 0 {
+			continue
+		}
+		disagreements = append(disagreements, Disagreement{MatchID: submissions[0].MatchID, MatchNum: submissions[0].MatchNum, Team: submissions[0].Team, Fields: fields, Submissions: submissions})
+	}
+	sort.Slice(disagreements, func(i, j int) bool {
+		if disagreements[i].MatchNum == disagreements[j].MatchNum {
+			return disagreements[i].Team < disagreements[j].Team
+		}
+		return disagreements[i].MatchNum < disagreements[j].MatchNum
+	})
+	return disagreements
+}
+
+//disagreeingFields lists the names of the fields that scouts disagree on
+func disagreeingFields(submissions []db.MatchData, tolerance int) []string {
+	fields := make([]string, 0)
+	for _, name := range ScoutedCountNames {
+		low, high := math.MaxInt32, math.MinInt32
+		for _, d := range submissions {
+			val := ScoutedCounts(d)[name]
+			if val < low {
+				low = val
+			}
+			if val > high {
+				high = val
+			}
+		}
+		if high-low > tolerance {
+			fields = append(fields, name)
+		}
+	}
+	for _, name := range ScoutedChoiceNames {
+		first := ScoutedChoices(submissions[0])[name]
+		for _, d := range submissions[1:] {
+			if ScoutedChoices(d)[name] != first {
+				fields = append(fields, name)
+				break
+			}
+		}
+	}
+	return fields
+}
+
+//ScoutedCountNames lists the counted fields of scouter data in the order they appear on the scouting form
+var ScoutedCountNames = []string{"AutoHighBalls", "AutoBackBalls", "AutoLowBalls", "AutoShots", "AutoPickups", "ShotQuantity", "LowFuel", "HighFuel", "BackFuel", "StageOneTime", "StageTwoTime", "Fouls", "TechFouls", "ClimbTime", "DefenseTime", "DefenseQuality"}
+
+//ScoutedChoiceNames lists the yes/no and multiple choice fields of scouter data in the order they appear on the scouting form
+var ScoutedChoiceNames = []string{"AutoLineCross", "StageOneComplete", "StageTwoComplete", "Card", "Climbed", "Balanced", "ClimbAttempted", "Disabled", "Tipped", "NoShow"}
+
+//ScoutedCounts gets the counted fields of scouter data by name
+func ScoutedCounts(d db.MatchData) map[string]int {
+	return map[string]int{"AutoHighBalls": d.AutoHighBalls, "AutoBackBalls": d.AutoBackBalls, "AutoLowBalls": d.AutoLowBalls, "AutoShots": d.AutoShots, "AutoPickups": d.AutoPickups, "ShotQuantity": d.ShotQuantity, "LowFuel": d.LowFuel, "HighFuel": d.HighFuel, "BackFuel": d.BackFuel, "StageOneTime": d.StageOneTime, "StageTwoTime": d.StageTwoTime, "Fouls": d.Fouls, "TechFouls": d.TechFouls, "ClimbTime": d.ClimbTime, "DefenseTime": d.DefenseTime, "DefenseQuality": d.DefenseQuality}
+}
+
+//ScoutedChoices gets the yes/no and multiple choice fields of scouter data by name
+func ScoutedChoices(d db.MatchData) map[string]string {
+	return map[string]string{"AutoLineCross": fmt.Sprint(d.AutoLineCross), "StageOneComplete": fmt.Sprint(d.StageOneComplete), "StageTwoComplete": fmt.Sprint(d.StageTwoComplete), "Card": d.Card, "Climbed": d.Climbed, "Balanced": fmt.Sprint(d.Balanced), "ClimbAttempted": fmt.Sprint(d.ClimbAttempted), "Disabled": fmt.Sprint(d.Disabled), "Tipped": fmt.Sprint(d.Tipped), "NoShow": fmt.Sprint(d.NoShow)}
+}
+
 //DemocraticeCensus tries to find answers which are most common and picks them
 
 //MeanCensus averages the answers
@@ -34,13 +34,20 @@ func init() {
 
 // YAML describes the structure of the YAML configuration file. See https://godoc.org/gopkg.in/yaml.v3#Marshal for more information.
 type YAML struct {
-	DatabaseBackupFrequency string `yaml:"DatabaseBackupFrequency"`
-	DatabaseBackupPath      string `yaml:"DatabaseBackupPath"`
-	DatabasePath            string `yaml:"DatabasePath"`
-	LogPath                 string `yaml:"LogPath"`
-	Port                    int    `yaml:"Port"`
-	TBAAuthKey              string `yaml:"TBAAuthKey"`
-	Verbosity               int    `yaml:"Verbosity"`
+	DatabaseBackupFrequency string  `yaml:"DatabaseBackupFrequency"`
+	DatabaseBackupPath      string  `yaml:"DatabaseBackupPath"`
+	DatabasePath            string  `yaml:"DatabasePath"`
+	DisagreementTolerance   int     `yaml:"DisagreementTolerance"`
+	LogPath                 string  `yaml:"LogPath"`
+	Port                    int     `yaml:"Port"`
+	PriorMatches            int     `yaml:"PriorMatches"`
+	ReconcileThreshold      int     `yaml:"ReconcileThreshold"`
+	ReliabilityWeight       float64 `yaml:"ReliabilityWeight"`
+	ShareScoringGaps        bool    `yaml:"ShareScoringGaps"`
+	TBAAuthKey              string  `yaml:"TBAAuthKey"`
+	TBASyncInterval         int     `yaml:"TBASyncInterval"`
+	TBAWebhookSecret        string  `yaml:"TBAWebhookSecret"`
+	Verbosity               int     `yaml:"Verbosity"`
 }
 
 /*
@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"strconv"
 	"strings"
+	"sync/atomic"
 	"time"
 
 	"database/sql"
@@ -15,8 +16,7 @@ import (
 	"github.com/google/uuid"
 	"github.com/raja/argon2pw"
 
-	// Comment to make golint happy.
-	_ "github.com/mattn/go-sqlite3"
+	"github.com/mattn/go-sqlite3"
 )
 
 /*
@@ -30,17 +30,112 @@ DatabasePath is the path to the directory which holds the databases.
 */
 var DatabasePath string
 
-var dbUsers *sql.DB
-var dbTeams *sql.DB
-var dbCampaigns *sql.DB
+var dbUsers *countedDB
+var dbTeams *countedDB
+var dbCampaigns *countedDB
+
+/*
+countedDB is a database which counts every query run on it, including those run in its transactions. See QueryCount.
+*/
+type countedDB struct {
+	*sql.DB
+}
+
+var queryCount uint64
+
+/*
+QueryCount gets how many queries have been run across all of the databases since the server started.
+*/
+func QueryCount() uint64 {
+	return atomic.LoadUint64(&queryCount)
+}
+
+func (db *countedDB) Exec(query string, args ...interface{}) (sql.Result, error) {
+	atomic.AddUint64(&queryCount, 1)
+	return db.DB.Exec(query, args...)
+}
+
+func (db *countedDB) Query(query string, args ...interface{}) (*sql.Rows, error) {
+	atomic.AddUint64(&queryCount, 1)
+	return db.DB.Query(query, args...)
+}
+
+func (db *countedDB) QueryRow(query string, args ...interface{}) *sql.Row {
+	atomic.AddUint64(&queryCount, 1)
+	return db.DB.QueryRow(query, args...)
+}
+
+func (db *countedDB) Begin() (*countedTx, error) {
+	tx, err := db.DB.Begin()
+	return &countedTx{tx}, err
+}
+
+/*
+countedTx is a transaction on a countedDB, which counts its queries along with the database's.
+*/
+type countedTx struct {
+	*sql.Tx
+}
+
+func (tx *countedTx) Exec(query string, args ...interface{}) (sql.Result, error) {
+	atomic.AddUint64(&queryCount, 1)
+	return tx.Tx.Exec(query, args...)
+}
+
+func (tx *countedTx) Query(query string, args ...interface{}) (*sql.Rows, error) {
+	atomic.AddUint64(&queryCount, 1)
+	return tx.Tx.Query(query, args...)
+}
+
+func (tx *countedTx) QueryRow(query string, args ...interface{}) *sql.Row {
+	atomic.AddUint64(&queryCount, 1)
+	return tx.Tx.QueryRow(query, args...)
+}
+
+func init() {
+	// The teams database is opened with the campaigns database attached as "campaigns", so results can be joined with the matches and competitors they refer to in one query. SQLite attaches databases per connection, so this happens whenever a connection is opened.
+	sql.Register("sqlite3_campaigns", &sqlite3.SQLiteDriver{
+		ConnectHook: func(conn *sqlite3.SQLiteConn) error {
+			_, err := conn.Exec(fmt.Sprintf("ATTACH DATABASE '%s' AS campaigns", DatabasePath+"campaigns.db"), nil)
+			return err
+		},
+	})
+}
+
+/*
+ResultHook is called after a competitor's scouted results for a match change, either because a scout submitted data or because a supervisor ruled on it.
+*/
+type ResultHook func(eventID, matchID string, teamNum int)
+
+var resultHooks []ResultHook
 
 /*
 Schedule describes the current Campaign / Event / Match a team is contributing to.
 */
 type Schedule struct {
-	matchID string
+	CampaignID string
+	EventID    string // Empty to follow whichever of the campaign's events is taking place.
+	MatchID    string // Empty until the team's admins pick a current match.
+	Mode       string // One of ScoutingModes.
+}
+
+/*
+ReplaySession is a team's practice scouting session on a recorded event, which is loaded into a sandbox campaign of its own.
+*/
+type ReplaySession struct {
+	Fixture    string // Which recorded event is being replayed.
+	CampaignID string // The sandbox campaign.
+	EventID    string
+	Previous   Schedule // What the team was scouting before the session, which is restored when it ends.
+	Started    string
+	Ended      string // Empty while the session is running.
 }
 
+/*
+ScoutingModes are the kinds of scouting a team can be doing. Scouts are sent to the team's mode when they don't ask for one.
+*/
+var ScoutingModes = []string{"match", "pit"}
+
 /*
 TeamData describes the most of the data regarding a team.
 */
@@ -49,7 +144,7 @@ type TeamData struct {
 	TeamName           string
 	TeamMembers        map[string]string // UserID and UserType.
 	AvaliableCampaigns map[string]bool   // List of CampaignIDs a team may write to. Bool indicates if team has write access, FALSE = read only.
-	Schedule           []string          // CampaignID, EventID, and MatchID for the team's current scouting.
+	Schedule           Schedule
 }
 
 /*
@@ -105,9 +200,184 @@ type MatchData struct {
 	Climbed          string
 	Balanced         bool
 	ClimbTime        int
+	DefenseTime      int // Seconds spent playing defense.
+	DefenseQuality   int // How well the robot defended, from 0 (did not defend) to 3. See DefenseQualityNames.
+	ClimbAttempted   bool
+	Disabled         bool // The robot broke down or was disabled during the match.
+	Tipped           bool
+	NoShow           bool // The robot never showed up for the match.
 	Comments         string
+	ScoutID          string // The results entry this data was read from. Empty for resolved data.
+	UserID           string // The user who scouted this data.
+	Ruling           string // A supervisor's ruling on this entry. See RulingAuthoritative and RulingExcluded.
+}
+
+/*
+ShiftRules describes the rules a team's scout shift schedule is generated from.
+*/
+type ShiftRules struct {
+	ScoutsPerMatch int      // How many scouts are on shift each match.
+	MaxConsecutive int      // The most matches in a row a scout may be on shift.
+	BreakLength    int      // How many matches a scout must sit out after MaxConsecutive matches in a row.
+	PitCrew        []string // Users who are never on shift during the team's own matches.
+}
+
+/*
+DefaultShiftRules are the shift rules of teams which haven't set their own.
+*/
+var DefaultShiftRules = ShiftRules{ScoutsPerMatch: 6, MaxConsecutive: 5, BreakLength: 2, PitCrew: []string{}}
+
+/*
+PitData describes a pit scouting entry for a team.
+*/
+type PitData struct {
+	Team      int
+	TeamName  string
+	CycleTime int
+	Comments  string
+}
+
+/*
+PitPlanEntry is a competitor in a team's pit scouting plan for an event.
+*/
+type PitPlanEntry struct {
+	Team    int
+	UserID  string   // The pit scout the competitor is assigned to.
+	Status  string   // One of PitStatuses.
+	Missing []string // The fields and photos still missing from the competitor's pit scouting. See PitFields.
+}
+
+/*
+PitStatuses are how far along pit scouting of a competitor is, in order.
+*/
+var PitStatuses = []string{"not started", "needs follow-up", "done"}
+
+/*
+PitFields are the parts of a pit scouting entry which must be filled in before a competitor is done.
+*/
+var PitFields = []string{"team name", "cycle time", "comments", "photo"}
+
+/*
+DefenseQualityNames names each defense quality a scout can record, in order
+*/
+var DefenseQualityNames = []string{"none", "poor", "fair", "good"}
+
+/*
+ScheduledMatch describes a match on an event's schedule and the competitors on each alliance, in station order.
+*/
+type ScheduledMatch struct {
+	MatchID  string
+	MatchNum int
+	Status   string // Empty, or one of MatchStatuses.
+	Red      []int
+	Blue     []int
+}
+
+/*
+ImportedMatch is a match copied from The Blue Alliance's schedule.
+*/
+type ImportedMatch struct {
+	Key       string // TBA's match key, which finds the match again when the event is imported again.
+	CompLevel string // qm for qualifications, otherwise ef, qf, sf or f.
+	SetNumber int    // Which series a playoff match is in.
+	MatchNum  int
+	Time      int64 // Scheduled start in Unix time, or 0 if it isn't known.
+	Red       []int // Team numbers in station order.
+	Blue      []int
+}
+
+/*
+Ranking is a competitor's official place in an event's qualification rankings.
+*/
+type Ranking struct {
+	Team         int
+	Rank         int
+	Wins         int
+	Losses       int
+	Ties         int
+	Played       int
+	RankingScore float64
+}
+
+/*
+Prior is a competitor's ratings from before an event, on the same scales as the ratings calc gives it from scouting there. They stand in for scouting until enough of its matches at the event are scouted.
+*/
+type Prior struct {
+	Team       int
+	Source     string // Where the ratings came from: tba, campaigns, or both.
+	Matches    int    // How many earlier matches the ratings are from.
+	Overall    int
+	Auto       int
+	Shooting   int
+	ColorWheel int
+	Climbing   int
+	Fouls      int
+}
+
+/*
+MatchStatuses are the ways a match can stray from the schedule. Delayed matches are skipped when a team advances to its next match. Marking a match replayed excludes the results already scouted for it, so it is scouted again. See VoidMatchResults.
+*/
+var MatchStatuses = []string{"delayed", "replayed"}
+
+/*
+ScoutAssignment is the competitor a scout is assigned to in a match, and whether they have seen the assignment.
+*/
+type ScoutAssignment struct {
+	UserID string
+	Team   int
+	Opened bool // The scout has opened the scouting form since they were assigned.
+}
+
+/*
+OfficialScore is an alliance's official score breakdown for a match.
+*/
+type OfficialScore struct {
+	MatchID          string
+	MatchNum         int
+	Alliance         string
+	AutoPoints       int
+	TeleopCellPoints int
+	EndgamePoints    int
+	FoulPoints       int // Points awarded to the alliance for the other alliance's fouls.
+	TotalPoints      int
+}
+
+/*
+PickListEntry is a competitor on a pick list.
+*/
+type PickListEntry struct {
+	Number int
+	Struck bool // Whether the competitor has been picked by another alliance.
+}
+
+/*
+PickListChange is an entry in a pick list's history.
+*/
+type PickListChange struct {
+	ListType string
+	UserID   string
+	Time     string
+	Action   string
+	List     []PickListEntry // The list after the change.
 }
 
+/*
+Pick list types.
+*/
+const (
+	PickListFirst  = "first"
+	PickListSecond = "second"
+)
+
+/*
+Supervisor rulings on conflicting scouting data.
+An authoritative entry replaces every other entry for the same robot in the same match. An excluded entry is ignored.
+*/
+const (
+	RulingAuthoritative = "authoritative"
+	RulingExcluded      = "excluded"
+)
+
 /*
 GENERAL FUNCTIONS
 */
@@ -149,17 +419,17 @@ Its name is a play on the GNU program "touch", the idiom "[to] touch base", and
 */
 func TouchBase(databasePath string) {
 	DatabasePath = databasePath
-	newDatabase := func(databaseName string) *sql.DB {
-		db, err := sql.Open("sqlite3", DatabasePath+databaseName+".db")
+	newDatabase := func(databaseName, driver string) *countedDB {
+		db, err := sql.Open(driver, DatabasePath+databaseName+".db")
 		accessCheck(err)
-		return db
+		return &countedDB{db}
 	}
 
 	err := os.MkdirAll(DatabasePath, 0755)
 	accessCheck(err)
 
 	// Users.
-	dbUsers = newDatabase("users")
+	dbUsers = newDatabase("users", "sqlite3")
 	dbUsers.Exec("CREATE TABLE IF NOT EXISTS users ( userid TEXT PRIMARY KEY UNIQUE NOT NULL, username TEXT NOT NULL UNIQUE, password TEXT NOT NULL, firstname TEXT, lastname TEXT, email TEXT, lastseen TEXT )") // TODO: Add support for N+ contact options; via linked table?
 	dbUsers.Exec("CREATE TABLE IF NOT EXISTS sysadmins ( userid TEXT PRIMARY KEY UNIQUE NOT NULL )")                                                                                                              // List of users which are SysAdmins.
 
@@ -175,38 +445,85 @@ func TouchBase(databasePath string) {
 	}
 
 	// Scouting teams.
-	dbTeams = newDatabase("teams")
+	dbTeams = newDatabase("teams", "sqlite3_campaigns")
 	dbTeams.Exec("CREATE TABLE IF NOT EXISTS teams ( teamid TEXT PRIMARY KEY UNIQUE NOT NULL, number TEXT UNIQUE, name TEXT NOT NULL, schedule TEXT NOT NULL )") // A team.
 	dbTeams.Exec("CREATE TABLE IF NOT EXISTS members ( userid TEXT, teamid TEXT NOT NULL, usertype TEXT NOT NULL )")                                             // The members on a team. UserType is either member or admin.
 	dbTeams.Exec("CREATE TABLE IF NOT EXISTS requestMembers ( userid TEXT, teamid TEXT NOT NULL )")                                                              // Membership requests for teams
 	dbTeams.Exec("CREATE TABLE IF NOT EXISTS participating ( teamid TEXT PRIMARY KEY NOT NULL, eventid TEXT NOT NULL, schedule TEXT )")                          // What events a team is participating in. If a team is currently running a campaign, they must have *some* event they are participating in. A team is scouting all matches during an event, of course.
 
 	dbTeams.Exec("CREATE TABLE IF NOT EXISTS results ( scoutid TEXT PRIMARY KEY, campaignid TEXT NOT NULL, eventid TEXT NOT NULL, matchid TEXT NOT NULL, userid TEXT NOT NULL, competitorid TEXT NOT NULL, matchnumber INTEGER NOT NULL, alliance STRING, autoLineCross BIT, autoLowBalls INTEGER, autoHighBalls INTEGER, autoBackBalls INTEGER, autoShots, autoPickups INTEGER, shotQuantity INTEGER, lowFuel INTEGER, highFuel INTEGER, backFuel INTEGER, stageOneComplete BIT, stageOneTime INTEGER, stageTwoComplete BIT, stageTwoTime INTEGER, fouls INTEGER, techFouls INTEGER, card TEXT, climbed TEXT, balanced BIT, climbtime INTEGER, comments TEXT )") // A team's scouted results. Any number of teams may scout for the same campaign / event / match at the same time.
+	// Columns added to results after it was first created. These fail harmlessly once the column exists.
+	dbTeams.Exec("ALTER TABLE results ADD COLUMN defenseTime INTEGER NOT NULL DEFAULT 0")    // Seconds the robot spent playing defense.
+	dbTeams.Exec("ALTER TABLE results ADD COLUMN defenseQuality INTEGER NOT NULL DEFAULT 0") // How well the robot defended. See DefenseQualityNames.
+	dbTeams.Exec("ALTER TABLE results ADD COLUMN climbAttempted BIT NOT NULL DEFAULT 0")     // Whether the robot tried to climb, whether or not it made it.
+	dbTeams.Exec("ALTER TABLE results ADD COLUMN disabled BIT NOT NULL DEFAULT 0")           // Whether the robot broke down or was disabled during the match.
+	dbTeams.Exec("ALTER TABLE results ADD COLUMN tipped BIT NOT NULL DEFAULT 0")             // Whether the robot tipped over.
+	dbTeams.Exec("ALTER TABLE results ADD COLUMN noShow BIT NOT NULL DEFAULT 0")             // Whether the robot never showed up for the match.
+
+	dbTeams.Exec("CREATE TABLE IF NOT EXISTS rulings ( scoutid TEXT PRIMARY KEY NOT NULL, ruling TEXT NOT NULL, userid TEXT NOT NULL, time TEXT )")                                                                                                                                                                             // Supervisor rulings on conflicting results. Ruling is either authoritative or excluded.
+	dbTeams.Exec("CREATE TABLE IF NOT EXISTS picklists ( teamid TEXT NOT NULL, eventid TEXT NOT NULL, listtype TEXT NOT NULL, position INTEGER NOT NULL, number INTEGER NOT NULL, struck BIT )")                                                                                                                                // A team's ordered pick lists for alliance selection. ListType is either first or second.
+	dbTeams.Exec("CREATE TABLE IF NOT EXISTS picklisthistory ( historyid TEXT PRIMARY KEY NOT NULL, teamid TEXT NOT NULL, eventid TEXT NOT NULL, listtype TEXT NOT NULL, userid TEXT NOT NULL, time TEXT NOT NULL, action TEXT, list TEXT )")                                                                                   // Every change made to a pick list, along with the list as it was afterwards.
+	dbTeams.Exec("CREATE TABLE IF NOT EXISTS priorities ( teamid TEXT NOT NULL, eventid TEXT NOT NULL, competitorid TEXT NOT NULL, priority INTEGER NOT NULL, PRIMARY KEY ( teamid, eventid, competitorid ) )")                                                                                                                 // How much a team wants each competitor at an event scouted. Competitors without a priority have priority 1.
+	dbTeams.Exec("CREATE TABLE IF NOT EXISTS assignments ( teamid TEXT NOT NULL, matchid TEXT NOT NULL, userid TEXT NOT NULL, competitorid TEXT NOT NULL, time TEXT, PRIMARY KEY ( teamid, matchid, userid ) )")                                                                                                                // Which competitor each of a team's scouts is assigned to scout in a match.
+	dbTeams.Exec("ALTER TABLE assignments ADD COLUMN opened TEXT NOT NULL DEFAULT ''")                                                                                                                                                                                                                                          // When the scout opened the scouting form for their assignment. Fails harmlessly once the column exists.
+	dbTeams.Exec("CREATE TABLE IF NOT EXISTS shiftrules ( teamid TEXT PRIMARY KEY NOT NULL, scoutspermatch INTEGER, maxconsecutive INTEGER, breaklength INTEGER, pitcrew TEXT )")                                                                                                                                               // The rules a team's scout shift schedule is generated from. PitCrew is a comma separated list of userids.
+	dbTeams.Exec("CREATE TABLE IF NOT EXISTS shifts ( teamid TEXT NOT NULL, eventid TEXT NOT NULL, matchid TEXT NOT NULL, userid TEXT NOT NULL, PRIMARY KEY ( teamid, matchid, userid ) )")                                                                                                                                     // Which of a team's scouts are on shift for each match at an event.
+	dbTeams.Exec("CREATE TABLE IF NOT EXISTS pitplans ( teamid TEXT NOT NULL, eventid TEXT NOT NULL, competitorid TEXT NOT NULL, userid TEXT NOT NULL, status TEXT NOT NULL, missing TEXT, PRIMARY KEY ( teamid, eventid, competitorid ) )")                                                                                    // Which pit scout each competitor at an event is assigned to and how far along they are. Missing is a comma separated list of PitFields.
+	dbTeams.Exec("CREATE TABLE IF NOT EXISTS schedules ( teamid TEXT PRIMARY KEY NOT NULL, campaignid TEXT NOT NULL, eventid TEXT NOT NULL, matchid TEXT NOT NULL, mode TEXT NOT NULL )")                                                                                                                                       // What each team is scouting right now. See Schedule.
+	dbTeams.Exec("CREATE TABLE IF NOT EXISTS replays ( teamid TEXT NOT NULL, fixture TEXT NOT NULL, campaignid TEXT NOT NULL, eventid TEXT NOT NULL, previouscampaignid TEXT NOT NULL, previouseventid TEXT NOT NULL, previousmatchid TEXT NOT NULL, previousmode TEXT NOT NULL, started TEXT NOT NULL, ended TEXT NOT NULL )") // Each team's practice scouting sessions on recorded events. See ReplaySession.
+	// Schedules used to be kept as a bare campaignid in teams.schedule. Teams without a schedule yet have theirs copied over.
+	dbTeams.Exec("INSERT OR IGNORE INTO schedules SELECT teamid, schedule, '', '', 'match' FROM teams WHERE schedule!=''")
 
 	// Create a default SysAdmin team if it does not exist.
 
 	// Campaigns. Stores information about campaigns but does not store the results associated with them.
-	dbCampaigns = newDatabase("campaigns")
+	dbCampaigns = newDatabase("campaigns", "sqlite3")
 	dbCampaigns.Exec("CREATE TABLE IF NOT EXISTS campaigns ( campaignid TEXT PRIMARY KEY UNIQUE NOT NULL, owner TEXT NOT NULL, name TEXT NOT NULL )")                                            // TODO: Add more information about each campaign. Campaign owner is a teamid. If campaign owner is all zeros, campaign is global.
 	dbCampaigns.Exec("CREATE TABLE IF NOT EXISTS events ( eventid TEXT PRIMARY KEY NOT NULL, campaignid TEXT NOT NULL, name TEXT NOT NULL, location TEXT, starttime INTEGER, endtime INTEGER )") // TODO: Add more information about each event.
+	dbCampaigns.Exec("ALTER TABLE events ADD COLUMN tbakey TEXT NOT NULL DEFAULT ''")                                                                                                            // The Blue Alliance's key for the event, if it was imported from there. Fails harmlessly once the column exists.
 	dbCampaigns.Exec("CREATE TABLE IF NOT EXISTS matches ( matchid TEXT PRIMARY KEY NOT NULL, eventid TEXT NOT NULL, matchnumber INTEGER NOT NULL, active BIT )")                                // TODO: Add more information about each match.
+	dbCampaigns.Exec("ALTER TABLE matches ADD COLUMN status TEXT NOT NULL DEFAULT ''")                                                                                                           // Empty, or one of MatchStatuses. Fails harmlessly once the column exists.
+	dbCampaigns.Exec("ALTER TABLE matches ADD COLUMN complevel TEXT NOT NULL DEFAULT 'qm'")                                                                                                      // qm for qualifications, otherwise ef, qf, sf or f. Match numbers only count up within a competition level.
+	dbCampaigns.Exec("ALTER TABLE matches ADD COLUMN setnumber INTEGER NOT NULL DEFAULT 0")                                                                                                      // Which series a playoff match is in.
+	dbCampaigns.Exec("ALTER TABLE matches ADD COLUMN time INTEGER NOT NULL DEFAULT 0")                                                                                                           // Scheduled start in Unix time, or 0 if it isn't known.
+	dbCampaigns.Exec("ALTER TABLE matches ADD COLUMN tbakey TEXT NOT NULL DEFAULT ''")                                                                                                           // The Blue Alliance's key for the match, if it was imported from there.
 	dbCampaigns.Exec("CREATE TABLE IF NOT EXISTS pitscout ( pitscoutid TEXT PRIMARY KEY NOT NULL, competitorid TEXT NOT NULL, campaignid TEXT NOT NULL, teamname TEXT, cycletime INTEGER NOT NULL, comments TEXT )")
 	dbCampaigns.Exec("CREATE TABLE IF NOT EXISTS images ( imageid TEXT PRIMARY KEY, campaignid TEXT NOT NULL, competitorid TEXT NOT NULL, image TEXT NOT NULL )")
-	dbCampaigns.Exec("CREATE TABLE IF NOT EXISTS participants ( matchid TEXT PRIMARY KEY NOT NULL, competitorid TEXT UNIQUE NOT NULL) ")             // The participants in each match.
-	dbCampaigns.Exec("CREATE TABLE IF NOT EXISTS competitors ( competitorid TEXT PRIMARY KEY NOT NULL, number INTEGER UNIQUE, name TEXT NOT NULL )") // TODO: Add more information about each competing team.
+	// The original participants table could only hold one competitor per match and was never written to, so it is replaced.
+	var alliance string
+	errParticipants := dbCampaigns.QueryRow("SELECT alliance FROM participants LIMIT 1").Scan(&alliance)
+	if errParticipants != nil && errParticipants != sql.ErrNoRows {
+		dbCampaigns.Exec("DROP TABLE IF EXISTS participants")
+	}
+	dbCampaigns.Exec("CREATE TABLE IF NOT EXISTS participants ( matchid TEXT NOT NULL, competitorid TEXT NOT NULL, alliance TEXT NOT NULL, station INTEGER, PRIMARY KEY ( matchid, competitorid ) )")                                                                                               // The scheduled participants in each match. Station is 1, 2, or 3.
+	dbCampaigns.Exec("CREATE TABLE IF NOT EXISTS competitors ( competitorid TEXT PRIMARY KEY NOT NULL, number INTEGER UNIQUE, name TEXT NOT NULL )")                                                                                                                                                // TODO: Add more information about each competing team.
+	dbCampaigns.Exec("CREATE TABLE IF NOT EXISTS officialscores ( matchid TEXT NOT NULL, alliance TEXT NOT NULL, autopoints INTEGER, teleopcellpoints INTEGER, endgamepoints INTEGER, foulpoints INTEGER, totalpoints INTEGER, PRIMARY KEY ( matchid, alliance ) )")                                // Each alliance's official score breakdown for a match.
+	dbCampaigns.Exec("CREATE TABLE IF NOT EXISTS rankings ( eventid TEXT NOT NULL, competitorid TEXT NOT NULL, rank INTEGER NOT NULL, wins INTEGER, losses INTEGER, ties INTEGER, played INTEGER, rankingscore REAL, PRIMARY KEY ( eventid, competitorid ) )")                                      // Each competitor's official qualification ranking at an event.
+	dbCampaigns.Exec("CREATE TABLE IF NOT EXISTS priors ( eventid TEXT NOT NULL, competitorid TEXT NOT NULL, source TEXT NOT NULL, matches INTEGER, overall INTEGER, auto INTEGER, shooting INTEGER, colorwheel INTEGER, climbing INTEGER, fouls INTEGER, PRIMARY KEY ( eventid, competitorid ) )") // Each competitor's ratings from before an event. See Prior.
+	dbCampaigns.Exec("CREATE TABLE IF NOT EXISTS eventteams ( eventid TEXT NOT NULL, competitorid TEXT NOT NULL, PRIMARY KEY ( eventid, competitorid ) )")                                                                                                                                          // The competitors at each event, which are known before its schedule is out.
+
+	// Indexes on the columns results and matches are looked up by.
+	dbTeams.Exec("CREATE INDEX IF NOT EXISTS resultsevent ON results ( eventid, competitorid )")
+	dbTeams.Exec("CREATE INDEX IF NOT EXISTS resultsmatch ON results ( matchid, competitorid )")
+	dbTeams.Exec("CREATE INDEX IF NOT EXISTS resultscampaign ON results ( campaignid )")
+	dbTeams.Exec("CREATE INDEX IF NOT EXISTS resultscompetitor ON results ( competitorid )")
+	dbCampaigns.Exec("CREATE INDEX IF NOT EXISTS matchesevent ON matches ( eventid, matchnumber )")
+	dbCampaigns.Exec("CREATE INDEX IF NOT EXISTS pitscoutcompetitor ON pitscout ( competitorid, campaignid )")
+	dbCampaigns.Exec("CREATE INDEX IF NOT EXISTS imagescompetitor ON images ( competitorid, campaignid )")
 
 	//Reusing indicator for whether database was just made after all databases are written
 	//TODO these are for testing
 	if errQuery != nil {
 		var teamID, campaignID, eventID string
-		TeamCreate(4415, "epic robotz", "nothing")
+		TeamCreate(4415, "epic robotz")
 		dbTeams.QueryRow("SELECT teamid FROM teams").Scan(&teamID)
 		CampaignCreate(teamID, "00000000-0000-0000-0000-000000000000", "test")
 		dbCampaigns.QueryRow("SELECT campaignid FROM campaigns WHERE owner='00000000-0000-0000-0000-000000000000'").Scan(&campaignID)
 		CreateEvent(campaignID, "00000000-0000-0000-0000-000000000000", "event", "nowhere", 0, 900000)
 		dbCampaigns.QueryRow("SELECT eventid FROM events").Scan(&eventID)
 		CreateMatch(eventID, "00000000-0000-0000-0000-000000000000", 1, true)
-		dbTeams.Exec(fmt.Sprintf("UPDATE teams SET schedule='%s' WHERE teamid='%s'", campaignID, teamID))
+		SetSchedule(teamID, Schedule{CampaignID: campaignID, Mode: ScoutingModes[0]})
+		AddTeamMember("00000000-0000-0000-0000-000000000000", teamID, "admin")
 	}
 }
 
@@ -218,10 +535,12 @@ TEAM ADMIN FUNCTIONS
 
 /*
 TeamCreate creates a new team from a TeamData struct. Returns bool false and an error if unable to create team.
+The team isn't scouting anything until it is given a schedule with SetSchedule.
 */
-func TeamCreate(number int, name, schedule string) error {
+func TeamCreate(number int, name string) error {
 	teamID := uuid.New().String()
-	_, err := dbTeams.Exec(fmt.Sprintf("INSERT INTO teams VALUES ( '%s', '%v', '%s', '%s' )", teamID, number, name, schedule))
+	// teams.schedule is no longer used, but is kept so older databases still work.
+	_, err := dbTeams.Exec(fmt.Sprintf("INSERT INTO teams VALUES ( '%s', '%v', '%s', '' )", teamID, number, name))
 	return err
 }
 
@@ -250,17 +569,17 @@ func TeamList() (teams []string) {
 }
 
 /*
-TeamListFull returns teamID, teamNumber, teamName, and schedule for every team.
+TeamListFull returns teamID, teamNumber, teamName, and the campaignID, eventID and matchID of the team's schedule for every team.
 */
 func TeamListFull() map[string][]string {
-	rows, err := dbTeams.Query("SELECT teamid, number, name, schedule from teams")
+	rows, err := dbTeams.Query("SELECT teams.teamid, number, name, IFNULL(campaignid, ''), IFNULL(eventid, ''), IFNULL(matchid, '') from teams LEFT JOIN schedules ON teams.teamid=schedules.teamid")
 	defer rows.Close()
 	accessCheck(err)
 	results := make(map[string][]string)
-	var id, number, name, schedule string
+	var id, number, name, campaignID, eventID, matchID string
 	for rows.Next() {
-		rows.Scan(&id, &number, &name, &schedule)
-		results[id] = append(results[id], number, name, schedule)
+		rows.Scan(&id, &number, &name, &campaignID, &eventID, &matchID)
+		results[id] = append(results[id], number, name, campaignID, eventID, matchID)
 	}
 	return results
 }
@@ -272,6 +591,72 @@ func TeamQuery() {
 
 }
 
+/*
+GetTeamNumber gets a team's number from its uuid
+*/
+func GetTeamNumber(teamID string) (int, error) {
+	var number int
+	err := dbTeams.QueryRow(fmt.Sprintf("SELECT number FROM teams WHERE teamid='%s'", teamID)).Scan(&number)
+	return number, err
+}
+
+/*
+GetMemberType gets a user's type on a team, either member or admin. Returns an error if the user is not on the team.
+*/
+func GetMemberType(userID, teamID string) (string, error) {
+	var userType string
+	err := dbTeams.QueryRow(fmt.Sprintf("SELECT usertype FROM members WHERE userid='%s' AND teamid='%s'", userID, teamID)).Scan(&userType)
+	return userType, err
+}
+
+/*
+GetUserTeams gets the teamid of every team a user is a member of, in the order they joined.
+*/
+func GetUserTeams(userID string) ([]string, error) {
+	var teamID string
+	teams := make([]string, 0)
+	rows, err := dbTeams.Query(fmt.Sprintf("SELECT teamid FROM members WHERE userid='%s' ORDER BY rowid", userID))
+	if err != nil {
+		return teams, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		rows.Scan(&teamID)
+		teams = append(teams, teamID)
+	}
+	return teams, nil
+}
+
+/*
+AddTeamMember adds a user to a team as either a member or an admin, or changes their type if they are already on it.
+*/
+func AddTeamMember(userID, teamID, userType string) error {
+	_, err := dbTeams.Exec(fmt.Sprintf("DELETE FROM members WHERE userid='%s' AND teamid='%s'", userID, teamID))
+	if err != nil {
+		return err
+	}
+	_, err = dbTeams.Exec(fmt.Sprintf("INSERT INTO members VALUES ( '%s', '%s', '%s' )", userID, teamID, userType))
+	return err
+}
+
+/*
+GetTeamMembers gets the userid of every member of a team, in the order they joined.
+*/
+func GetTeamMembers(teamID string) ([]string, error) {
+	var userID string
+	members := make([]string, 0)
+	rows, err := dbTeams.Query(fmt.Sprintf("SELECT userid FROM members WHERE teamid='%s' ORDER BY rowid", teamID))
+	if err != nil {
+		return members, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		rows.Scan(&userID)
+		members = append(members, userID)
+	}
+	return members, nil
+}
+
 /*
 USER FUNCTIONS
 */
@@ -392,32 +777,81 @@ func UserList() map[string]string {
 	return results
 }
 
+/*
+GetSchedule gets what a team is scouting right now. Returns sql.ErrNoRows if the team has no schedule.
+*/
+func GetSchedule(teamID string) (Schedule, error) {
+	var schedule Schedule
+	err := dbTeams.QueryRow(fmt.Sprintf("SELECT campaignid, eventid, matchid, mode FROM schedules WHERE teamid='%s'", teamID)).Scan(&schedule.CampaignID, &schedule.EventID, &schedule.MatchID, &schedule.Mode)
+	return schedule, err
+}
+
+/*
+SetSchedule sets what a team is scouting right now, replacing its old schedule.
+*/
+func SetSchedule(teamID string, schedule Schedule) error {
+	_, err := dbTeams.Exec(fmt.Sprintf("INSERT OR REPLACE INTO schedules VALUES ( '%s', '%s', '%s', '%s', '%s' )", teamID, schedule.CampaignID, schedule.EventID, schedule.MatchID, schedule.Mode))
+	if err != nil {
+		log.Errorf("Unable to set schedule for team %s: %s", teamID, err.Error())
+	}
+	return err
+}
+
+/*
+StartReplaySession records that a team has started a practice scouting session.
+*/
+func StartReplaySession(teamID string, session ReplaySession) error {
+	_, err := dbTeams.Exec(fmt.Sprintf("INSERT INTO replays VALUES ( '%s', '%s', '%s', '%s', '%s', '%s', '%s', '%s', '%s', '' )", teamID, session.Fixture, session.CampaignID, session.EventID, session.Previous.CampaignID, session.Previous.EventID, session.Previous.MatchID, session.Previous.Mode, time.Now().Format("2006-01-02 15:04:05")))
+	if err != nil {
+		log.Errorf("Unable to start replay session for team %s: %s", teamID, err.Error())
+	}
+	return err
+}
+
+/*
+GetReplaySession gets a team's latest practice scouting session, whether or not it has ended.
+*/
+func GetReplaySession(teamID string) (ReplaySession, error) {
+	var session ReplaySession
+	err := dbTeams.QueryRow(fmt.Sprintf("SELECT fixture, campaignid, eventid, previouscampaignid, previouseventid, previousmatchid, previousmode, started, ended FROM replays WHERE teamid='%s' ORDER BY started DESC, rowid DESC LIMIT 1", teamID)).Scan(&session.Fixture, &session.CampaignID, &session.EventID, &session.Previous.CampaignID, &session.Previous.EventID, &session.Previous.MatchID, &session.Previous.Mode, &session.Started, &session.Ended)
+	return session, err
+}
+
+/*
+EndReplaySession records that a team's running practice scouting session has ended.
+*/
+func EndReplaySession(teamID string) error {
+	_, err := dbTeams.Exec(fmt.Sprintf("UPDATE replays SET ended='%s' WHERE teamid='%s' AND ended=''", time.Now().Format("2006-01-02 15:04:05"), teamID))
+	return err
+}
+
 /*
 GetTeamCampaign gets the uuid of the campaign with which a team is associated
 */
 func GetTeamCampaign(teamID string) (string, error) {
-	var campaign string
-	//TODO account for schedule as object instead of storing active campaign in it directly
-	err := dbTeams.QueryRow(fmt.Sprintf("SELECT schedule FROM teams WHERE teamid='%s'", teamID)).Scan(&campaign)
+	schedule, err := GetSchedule(teamID)
 	if err != nil {
 		return "", err
 	}
-	return campaign, nil
+	return schedule.CampaignID, nil
 }
 
 /*
 GetTeamSchedule gets the event and campaign in which a team is currently participating
 */
 func GetTeamSchedule(teamID string) (string, string, error) {
-	campaignid, err := GetTeamCampaign(teamID)
+	schedule, err := GetSchedule(teamID)
 	if err != nil {
 		return "", "", err
 	}
-	eventid, err := GetActiveCampaignEvent(campaignid)
+	if schedule.EventID != "" {
+		return schedule.CampaignID, schedule.EventID, nil
+	}
+	eventid, err := GetActiveCampaignEvent(schedule.CampaignID)
 	if err != nil {
 		return "", "", err
 	}
-	return campaignid, eventid, nil
+	return schedule.CampaignID, eventid, nil
 }
 
 /*
@@ -443,26 +877,73 @@ func StoreMatch(arr []string, agentid, teamid string) error {
 		competitorid = GetCompetitorID(data.Team)
 	}
 	scoutid := uuid.New().String()
-	result, errExec := dbTeams.Exec(fmt.Sprintf("INSERT INTO results VALUES ( '%s', '%s', '%s', '%s', '%s', '%s', '%v', '%s', '%v', '%v', '%v', '%v', '%v', '%v', '%v', '%v', '%v', '%v', '%v', '%v', '%v', '%v', '%v', '%v', '%v', '%v', '%v', '%v', '%s' )", scoutid, campaignid, eventid, data.MatchID, agentid, competitorid, data.MatchNum, data.Alliance, data.AutoLineCross, data.AutoLowBalls, data.AutoHighBalls, data.AutoBackBalls, data.AutoShots, data.AutoPickups, data.ShotQuantity, data.LowFuel, data.HighFuel, data.BackFuel, data.StageOneComplete, data.StageOneTime, data.StageTwoComplete, data.StageTwoTime, data.Fouls, data.TechFouls, data.Card, data.Climbed, data.Balanced, data.ClimbTime, escapeText(data.Comments)))
+	result, errExec := dbTeams.Exec(fmt.Sprintf("INSERT INTO results VALUES ( '%s', '%s', '%s', '%s', '%s', '%s', '%v', '%s', '%v', '%v', '%v', '%v', '%v', '%v', '%v', '%v', '%v', '%v', '%v', '%v', '%v', '%v', '%v', '%v', '%v', '%v', '%v', '%v', '%s', '%v', '%v', '%v', '%v', '%v', '%v' )", scoutid, campaignid, eventid, data.MatchID, agentid, competitorid, data.MatchNum, data.Alliance, data.AutoLineCross, data.AutoLowBalls, data.AutoHighBalls, data.AutoBackBalls, data.AutoShots, data.AutoPickups, data.ShotQuantity, data.LowFuel, data.HighFuel, data.BackFuel, data.StageOneComplete, data.StageOneTime, data.StageTwoComplete, data.StageTwoTime, data.Fouls, data.TechFouls, data.Card, data.Climbed, data.Balanced, data.ClimbTime, escapeText(data.Comments), data.DefenseTime, data.DefenseQuality, data.ClimbAttempted, data.Disabled, data.Tipped, data.NoShow))
 	if errExec != nil {
 		log.Errorf("Unable to write match scouting data to database: %s", errExec)
 		return errExec
 	}
 	log.Debugf("Match Write Result: %v", result)
+	resultChanged(eventid, data.MatchID, data.Team)
 	return nil
 }
 
+/*
+OnResultChange registers a hook to be called whenever a competitor's scouted results for a match change. Hooks should be registered before the server starts.
+*/
+func OnResultChange(hook ResultHook) {
+	resultHooks = append(resultHooks, hook)
+}
+
+/*
+resultChanged calls every registered result hook.
+*/
+func resultChanged(eventID, matchID string, teamNum int) {
+	for _, hook := range resultHooks {
+		hook(eventID, matchID, teamNum)
+	}
+}
+
+/*
+resultEntryChanged calls every registered result hook for the competitor and match of a results entry.
+*/
+func resultEntryChanged(scoutID string) {
+	var eventID, matchID, competitorID string
+	err := dbTeams.QueryRow(fmt.Sprintf("SELECT eventid, matchid, competitorid FROM results WHERE scoutid='%s'", scoutID)).Scan(&eventID, &matchID, &competitorID)
+	if err != nil {
+		log.Warnf("Unable to find results entry %s: %s", scoutID, err.Error())
+		return
+	}
+	resultChanged(eventID, matchID, GetCompetitorNumberFromID(competitorID))
+}
+
 /*
 arrToMatchStruct turns the data array into a match struct
 */
 func arrToMatchStruct(arr []string, eventid, agentid string) (*MatchData, error) {
 	var autoLineCross, stageOneComplete, stageTwoComplete bool
 	var alliance, card, climbed string
+	if len(arr) < 24 {
+		log.Errorf("Unable to read match data array: expected at least 24 values, got %v", len(arr))
+		return nil, errors.New("match data array is too short")
+	}
 	//cuts off the last value since comments can't be converted into integers
 	intarr, err := convertArrToInts(arr[:len(arr)-1])
 	if err != nil {
 		return nil, err
 	}
+	//forms from before defense and reliability were scouted leave them out, so they are only read when present
+	var defenseTime, defenseQuality int
+	var climbAttempted, disabled, tipped, noShow bool
+	if len(intarr) >= 25 {
+		defenseTime = intarr[23]
+		defenseQuality = intarr[24]
+	}
+	if len(intarr) >= 29 {
+		climbAttempted = intarr[25] == 1
+		disabled = intarr[26] == 1
+		tipped = intarr[27] == 1
+		noShow = intarr[28] == 1
+	}
 	matchid, err := matchIDFromNum(intarr[0], eventid)
 	if matchid == "" {
 		//TODO figure out if true value on matches is uselful under current system
@@ -492,7 +973,7 @@ func arrToMatchStruct(arr []string, eventid, agentid string) (*MatchData, error)
 	} else {
 		climbed = "none"
 	}
-	return &MatchData{MatchID: matchid, MatchNum: intarr[0], Team: intarr[1], Alliance: alliance, AutoLineCross: autoLineCross, AutoLowBalls: intarr[4], AutoHighBalls: intarr[5], AutoBackBalls: intarr[6], AutoShots: intarr[7], AutoPickups: intarr[8], ShotQuantity: intarr[9], LowFuel: intarr[10], HighFuel: intarr[11], BackFuel: intarr[12], StageOneComplete: stageOneComplete, StageOneTime: intarr[14], StageTwoComplete: stageTwoComplete, StageTwoTime: intarr[16], Fouls: intarr[17], TechFouls: intarr[18], Card: card, Climbed: climbed, Balanced: balanced, ClimbTime: intarr[22], Comments: arr[23]}, nil
+	return &MatchData{MatchID: matchid, MatchNum: intarr[0], Team: intarr[1], Alliance: alliance, AutoLineCross: autoLineCross, AutoLowBalls: intarr[4], AutoHighBalls: intarr[5], AutoBackBalls: intarr[6], AutoShots: intarr[7], AutoPickups: intarr[8], ShotQuantity: intarr[9], LowFuel: intarr[10], HighFuel: intarr[11], BackFuel: intarr[12], StageOneComplete: stageOneComplete, StageOneTime: intarr[14], StageTwoComplete: stageTwoComplete, StageTwoTime: intarr[16], Fouls: intarr[17], TechFouls: intarr[18], Card: card, Climbed: climbed, Balanced: balanced, ClimbTime: intarr[22], DefenseTime: defenseTime, DefenseQuality: defenseQuality, ClimbAttempted: climbAttempted, Disabled: disabled, Tipped: tipped, NoShow: noShow, Comments: arr[len(arr)-1]}, nil
 }
 
 func convertArrToInts(arr []string) ([]int, error) {
@@ -545,8 +1026,114 @@ func WritePitData(arr []string, userID, campaignID string) error {
 		return err
 	}
 	//inserts images into seperate table
-	writeImage(competitorID, campaignID, arr[4])
-	return nil
+	if len(arr) > 4 {
+		writeImage(competitorID, campaignID, arr[4])
+	}
+	return updatePitPlans(competitorID, campaignID)
+}
+
+/*
+GetPitPlan gets a team's pit scouting plan for an event, in team number order.
+*/
+func GetPitPlan(teamID, eventID string) ([]PitPlanEntry, error) {
+	var missing string
+	plan := make([]PitPlanEntry, 0)
+	rows, err := dbTeams.Query(fmt.Sprintf("SELECT IFNULL(campaigns.competitors.number, 0), userid, status, IFNULL(missing, '') FROM pitplans LEFT JOIN campaigns.competitors ON pitplans.competitorid=campaigns.competitors.competitorid WHERE teamid='%s' AND eventid='%s' ORDER BY campaigns.competitors.number", teamID, eventID))
+	if err != nil {
+		return plan, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var entry PitPlanEntry
+		err = rows.Scan(&entry.Team, &entry.UserID, &entry.Status, &missing)
+		if err != nil {
+			return plan, err
+		}
+		entry.Missing = make([]string, 0)
+		if missing != "" {
+			entry.Missing = strings.Split(missing, ",")
+		}
+		plan = append(plan, entry)
+	}
+	return plan, nil
+}
+
+/*
+WritePitPlan replaces a team's pit scouting plan for an event. Assignments maps each competitor's team number to the pit scout assigned to it.
+Each competitor's status is worked out from the pit scouting already done for it in the team's campaign.
+*/
+func WritePitPlan(teamID, eventID string, assignments map[int]string) error {
+	campaignID, err := GetTeamCampaign(teamID)
+	if err != nil {
+		return err
+	}
+	tx, err := dbTeams.Begin()
+	if err != nil {
+		return err
+	}
+	_, err = tx.Exec(fmt.Sprintf("DELETE FROM pitplans WHERE teamid='%s' AND eventid='%s'", teamID, eventID))
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	for teamNum, userID := range assignments {
+		competitorID := GetCompetitorID(teamNum)
+		if competitorID == "" {
+			CreateCompetitor(teamNum, "")
+			competitorID = GetCompetitorID(teamNum)
+		}
+		status, missing := pitProgress(competitorID, campaignID)
+		_, err = tx.Exec(fmt.Sprintf("INSERT INTO pitplans VALUES ( '%s', '%s', '%s', '%s', '%s', '%s' )", teamID, eventID, competitorID, userID, status, strings.Join(missing, ",")))
+		if err != nil {
+			tx.Rollback()
+			log.Errorf("Unable to write pit scouting plan for team %s: %s", teamID, err.Error())
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+/*
+SetPitStatus sets how far along pit scouting of a competitor is in a team's plan, such as to ask for a follow-up visit. The next pit scouting entry for the competitor sets it again.
+*/
+func SetPitStatus(teamID, eventID string, teamNum int, status string) error {
+	_, err := dbTeams.Exec(fmt.Sprintf("UPDATE pitplans SET status='%s' WHERE teamid='%s' AND eventid='%s' AND competitorid='%s'", status, teamID, eventID, GetCompetitorID(teamNum)))
+	return err
+}
+
+/*
+pitProgress works out how far along pit scouting of a competitor is in a campaign from its most recent pit scouting entry and whether it has a photo.
+*/
+func pitProgress(competitorID, campaignID string) (string, []string) {
+	var teamName, comments string
+	var cycleTime, images int
+	missing := make([]string, 0)
+	err := dbCampaigns.QueryRow(fmt.Sprintf("SELECT IFNULL(teamname, ''), cycletime, IFNULL(comments, '') FROM pitscout WHERE competitorid='%s' AND campaignid='%s' ORDER BY rowid DESC LIMIT 1", competitorID, campaignID)).Scan(&teamName, &cycleTime, &comments)
+	if err != nil {
+		return PitStatuses[0], PitFields
+	}
+	dbCampaigns.QueryRow(fmt.Sprintf("SELECT COUNT(*) FROM images WHERE competitorid='%s' AND campaignid='%s'", competitorID, campaignID)).Scan(&images)
+	for ind, empty := range []bool{teamName == "", cycleTime <= 0, comments == "", images == 0} {
+		if empty {
+			missing = append(missing, PitFields[ind])
+		}
+	}
+	if len(missing) > 0 {
+		return PitStatuses[1], missing
+	}
+	return PitStatuses[2], missing
+}
+
+/*
+updatePitPlans brings a competitor up to date in every pit scouting plan for the campaign's events after a new pit scouting entry.
+*/
+func updatePitPlans(competitorID, campaignID string) error {
+	status, missing := pitProgress(competitorID, campaignID)
+	_, err := dbTeams.Exec(fmt.Sprintf("UPDATE pitplans SET status='%s', missing='%s' WHERE competitorid='%s' AND eventid IN ( SELECT eventid FROM campaigns.events WHERE campaignid='%s' )", status, strings.Join(missing, ","), competitorID, campaignID))
+	if err != nil {
+		log.Errorf("Unable to update pit scouting plans for competitor %s: %s", competitorID, err.Error())
+	}
+	return err
 }
 
 /*
@@ -560,7 +1147,7 @@ func writeImage(competitorID, campaignid, image string) error {
 
 func matchIDFromNum(num int, eventid string) (string, error) {
 	var matchid string
-	err := dbCampaigns.QueryRow(fmt.Sprintf("SELECT matchid FROM matches WHERE eventid='%s' AND matchnumber='%v'", eventid, num)).Scan(&matchid)
+	err := dbCampaigns.QueryRow(fmt.Sprintf("SELECT matchid FROM matches WHERE eventid='%s' AND complevel='qm' AND matchnumber='%v'", eventid, num)).Scan(&matchid)
 	if err != nil {
 		log.Warnf("Failed to retrive match id for match #%v from event %s", num, eventid)
 		return "", err
@@ -574,13 +1161,16 @@ GetMatchResults gets scouter's data based on a match id
 func GetMatchResults(matchID, campaignID string) (*[]MatchData, error) {
 	var competitorID string
 	data := make([]MatchData, 0)
-	rows, err := dbTeams.Query(fmt.Sprintf("SELECT matchnumber, competitorid, autoLineCross, autoLowBalls, autoHighBalls, autoBackBalls, autoPickups, shotQuantity, lowFuel, highFuel, backFuel, stageOneComplete, stageOneTime, stageTwoComplete, stageTwoTime, fouls, techFouls, card, climbed, balanced, climbtime, comments FROM results WHERE matchID='%s'", matchID))
+	rows, err := dbTeams.Query(fmt.Sprintf("SELECT matchnumber, competitorid, autoLineCross, autoLowBalls, autoHighBalls, autoBackBalls, autoPickups, shotQuantity, lowFuel, highFuel, backFuel, stageOneComplete, stageOneTime, stageTwoComplete, stageTwoTime, fouls, techFouls, card, climbed, balanced, climbtime, defenseTime, defenseQuality, climbAttempted, disabled, tipped, noShow, comments, results.scoutid, results.userid, IFNULL(rulings.ruling, '') FROM results LEFT JOIN rulings ON results.scoutid=rulings.scoutid WHERE matchID='%s'", matchID))
+	if err != nil {
+		return &data, err
+	}
 	defer rows.Close()
 	for rows.Next() {
 		var d MatchData
-		err = rows.Scan(&d.MatchNum, &competitorID, &d.AutoLineCross, &d.AutoLowBalls, &d.AutoHighBalls, &d.AutoBackBalls, &d.AutoPickups, &d.ShotQuantity, &d.LowFuel, &d.HighFuel, &d.BackFuel, &d.StageOneComplete, &d.StageOneTime, &d.StageTwoComplete, &d.StageTwoTime, &d.Fouls, &d.TechFouls, &d.Card, &d.Climbed, &d.Balanced, &d.ClimbTime, &d.Comments)
+		err = rows.Scan(&d.MatchNum, &competitorID, &d.AutoLineCross, &d.AutoLowBalls, &d.AutoHighBalls, &d.AutoBackBalls, &d.AutoPickups, &d.ShotQuantity, &d.LowFuel, &d.HighFuel, &d.BackFuel, &d.StageOneComplete, &d.StageOneTime, &d.StageTwoComplete, &d.StageTwoTime, &d.Fouls, &d.TechFouls, &d.Card, &d.Climbed, &d.Balanced, &d.ClimbTime, &d.DefenseTime, &d.DefenseQuality, &d.ClimbAttempted, &d.Disabled, &d.Tipped, &d.NoShow, &d.Comments, &d.ScoutID, &d.UserID, &d.Ruling)
 		if err != nil {
-			return nil, err
+			return &data, err
 		}
 		d.MatchID = matchID
 		d.Team = GetCompetitorNumberFromID(competitorID)
@@ -589,26 +1179,24 @@ func GetMatchResults(matchID, campaignID string) (*[]MatchData, error) {
 }
 
 /*
-GetTeamResults gets scouter's data based on a team id
+GetTeamResults gets scouter's data for a team at an event
 */
-func GetTeamResults(teamNum int, campaignID string) (*[]MatchData, error) {
-	var matchEvent string
-	campaignEvent, _ := GetActiveCampaignEvent(campaignID)
+func GetTeamResults(teamNum int, eventID string) (*[]MatchData, error) {
 	competitorID := GetCompetitorID(teamNum)
 	data := make([]MatchData, 0)
-	rows, err := dbTeams.Query(fmt.Sprintf("SELECT matchid, matchnumber, autoLineCross, autoLowBalls, autoHighBalls, autoBackBalls, autoPickups, shotQuantity, lowFuel, highFuel, backFuel, stageOneComplete, stageOneTime, stageTwoComplete, stageTwoTime, fouls, techFouls, card, climbed, balanced, climbtime, comments FROM results WHERE competitorid='%s' AND eventid='%s'", competitorID, campaignEvent))
+	rows, err := dbTeams.Query(fmt.Sprintf("SELECT results.matchid, results.matchnumber, autoLineCross, autoLowBalls, autoHighBalls, autoBackBalls, autoPickups, shotQuantity, lowFuel, highFuel, backFuel, stageOneComplete, stageOneTime, stageTwoComplete, stageTwoTime, fouls, techFouls, card, climbed, balanced, climbtime, defenseTime, defenseQuality, climbAttempted, disabled, tipped, noShow, comments, results.scoutid, results.userid, IFNULL(rulings.ruling, '') FROM results JOIN campaigns.matches ON results.matchid=campaigns.matches.matchid LEFT JOIN rulings ON results.scoutid=rulings.scoutid WHERE results.competitorid='%s' AND campaigns.matches.eventid='%s'", competitorID, eventID))
+	if err != nil {
+		return &data, err
+	}
 	defer rows.Close()
 	for rows.Next() {
 		var d MatchData
-		err = rows.Scan(&d.MatchID, &d.MatchNum, &d.AutoLineCross, &d.AutoLowBalls, &d.AutoHighBalls, &d.AutoBackBalls, &d.AutoPickups, &d.ShotQuantity, &d.LowFuel, &d.HighFuel, &d.BackFuel, &d.StageOneComplete, &d.StageOneTime, &d.StageTwoComplete, &d.StageTwoTime, &d.Fouls, &d.TechFouls, &d.Card, &d.Climbed, &d.Balanced, &d.ClimbTime, &d.Comments)
+		err = rows.Scan(&d.MatchID, &d.MatchNum, &d.AutoLineCross, &d.AutoLowBalls, &d.AutoHighBalls, &d.AutoBackBalls, &d.AutoPickups, &d.ShotQuantity, &d.LowFuel, &d.HighFuel, &d.BackFuel, &d.StageOneComplete, &d.StageOneTime, &d.StageTwoComplete, &d.StageTwoTime, &d.Fouls, &d.TechFouls, &d.Card, &d.Climbed, &d.Balanced, &d.ClimbTime, &d.DefenseTime, &d.DefenseQuality, &d.ClimbAttempted, &d.Disabled, &d.Tipped, &d.NoShow, &d.Comments, &d.ScoutID, &d.UserID, &d.Ruling)
 		if err != nil {
-			return nil, err
+			return &data, err
 		}
 		d.Team = teamNum
-		matchEvent, _ = getMatchEvent(d.MatchID)
-		if matchEvent == campaignEvent {
-			data = append(data, d)
-		}
+		data = append(data, d)
 	}
 	return &data, nil
 }
@@ -616,11 +1204,10 @@ func GetTeamResults(teamNum int, campaignID string) (*[]MatchData, error) {
 /*
 GetTeamComments gets all comments for a team at a certain event
 */
-func GetTeamComments(teamNum int, campaignID string) ([]string, error) {
+func GetTeamComments(teamNum int, eventID string) ([]string, error) {
 	var comment string
 	comments := make([]string, 0)
 	teamID := GetCompetitorID(teamNum)
-	eventID, _ := GetActiveCampaignEvent(campaignID)
 	row, err := dbTeams.Query(fmt.Sprintf("SELECT comments FROM results WHERE competitorid='%s' AND eventid='%s'", teamID, eventID))
 	if err != nil {
 		return comments, err
@@ -633,18 +1220,31 @@ func GetTeamComments(teamNum int, campaignID string) ([]string, error) {
 }
 
 /*
-GetTeamMatchResults gets scouter's data based on a team id for a given match
+GetPitData gets the most recent pit scouting entry for a team in a campaign
 */
-func GetTeamMatchResults(teamNum int, matchID string) (*[]MatchData, error) {
+func GetPitData(teamNum int, campaignID string) (PitData, error) {
+	pit := PitData{Team: teamNum}
+	competitorID := GetCompetitorID(teamNum)
+	err := dbCampaigns.QueryRow(fmt.Sprintf("SELECT IFNULL(teamname, ''), cycletime, IFNULL(comments, '') FROM pitscout WHERE competitorid='%s' AND campaignid='%s' ORDER BY rowid DESC LIMIT 1", competitorID, campaignID)).Scan(&pit.TeamName, &pit.CycleTime, &pit.Comments)
+	return pit, err
+}
+
+/*
+GetTeamMatchResults gets scouter's data based on a team id for a given match
+*/
+func GetTeamMatchResults(teamNum int, matchID string) (*[]MatchData, error) {
 	data := make([]MatchData, 0)
 	competitorID := GetCompetitorID(teamNum)
-	rows, err := dbTeams.Query(fmt.Sprintf("SELECT matchnumber, autoLineCross, autoLowBalls, autoHighBalls, autoBackBalls, autoPickups, shotQuantity, lowFuel, highFuel, backFuel, stageOneComplete, stageOneTime, stageTwoComplete, stageTwoTime, fouls, techFouls, card, climbed, balanced, climbtime, comments FROM results WHERE competitorid='%s' AND matchid='%s'", competitorID, matchID))
+	rows, err := dbTeams.Query(fmt.Sprintf("SELECT matchnumber, autoLineCross, autoLowBalls, autoHighBalls, autoBackBalls, autoPickups, shotQuantity, lowFuel, highFuel, backFuel, stageOneComplete, stageOneTime, stageTwoComplete, stageTwoTime, fouls, techFouls, card, climbed, balanced, climbtime, defenseTime, defenseQuality, climbAttempted, disabled, tipped, noShow, comments, results.scoutid, results.userid, IFNULL(rulings.ruling, '') FROM results LEFT JOIN rulings ON results.scoutid=rulings.scoutid WHERE competitorid='%s' AND matchid='%s'", competitorID, matchID))
+	if err != nil {
+		return &data, err
+	}
 	defer rows.Close()
 	for rows.Next() {
 		var d MatchData
-		err = rows.Scan(&d.MatchNum, &d.AutoLineCross, &d.AutoLowBalls, &d.AutoHighBalls, &d.AutoBackBalls, &d.AutoPickups, &d.ShotQuantity, &d.LowFuel, &d.HighFuel, &d.BackFuel, &d.StageOneComplete, &d.StageOneTime, &d.StageTwoComplete, &d.StageTwoTime, &d.Fouls, &d.TechFouls, &d.Card, &d.Climbed, &d.Balanced, &d.ClimbTime, &d.Comments)
+		err = rows.Scan(&d.MatchNum, &d.AutoLineCross, &d.AutoLowBalls, &d.AutoHighBalls, &d.AutoBackBalls, &d.AutoPickups, &d.ShotQuantity, &d.LowFuel, &d.HighFuel, &d.BackFuel, &d.StageOneComplete, &d.StageOneTime, &d.StageTwoComplete, &d.StageTwoTime, &d.Fouls, &d.TechFouls, &d.Card, &d.Climbed, &d.Balanced, &d.ClimbTime, &d.DefenseTime, &d.DefenseQuality, &d.ClimbAttempted, &d.Disabled, &d.Tipped, &d.NoShow, &d.Comments, &d.ScoutID, &d.UserID, &d.Ruling)
 		if err != nil {
-			return nil, err
+			return &data, err
 		}
 		d.MatchID = matchID
 		d.Team = teamNum
@@ -654,19 +1254,21 @@ func GetTeamMatchResults(teamNum int, matchID string) (*[]MatchData, error) {
 }
 
 /*
-GetTeamMatches gets scouter's data based on a team id for a given match
+GetTeamMatches gets scouter's data for a team at an event
 */
-func GetTeamMatches(teamNum int, campaignid string) (*[]MatchData, error) {
+func GetTeamMatches(teamNum int, event string) (*[]MatchData, error) {
 	data := make([]MatchData, 0)
 	competitorID := GetCompetitorID(teamNum)
-	event, _ := GetActiveCampaignEvent(campaignid)
-	rows, err := dbTeams.Query(fmt.Sprintf("SELECT matchnumber, matchid, autoLineCross, autoLowBalls, autoHighBalls, autoBackBalls, autoPickups, shotQuantity, lowFuel, highFuel, backFuel, stageOneComplete, stageOneTime, stageTwoComplete, stageTwoTime, fouls, techFouls, card, climbed, balanced, climbtime, comments FROM results WHERE competitorid='%s' AND eventid='%s'", competitorID, event))
+	rows, err := dbTeams.Query(fmt.Sprintf("SELECT matchnumber, matchid, autoLineCross, autoLowBalls, autoHighBalls, autoBackBalls, autoPickups, shotQuantity, lowFuel, highFuel, backFuel, stageOneComplete, stageOneTime, stageTwoComplete, stageTwoTime, fouls, techFouls, card, climbed, balanced, climbtime, defenseTime, defenseQuality, climbAttempted, disabled, tipped, noShow, comments, results.scoutid, results.userid, IFNULL(rulings.ruling, '') FROM results LEFT JOIN rulings ON results.scoutid=rulings.scoutid WHERE competitorid='%s' AND eventid='%s'", competitorID, event))
+	if err != nil {
+		return &data, err
+	}
 	defer rows.Close()
 	for rows.Next() {
 		var d MatchData
-		err = rows.Scan(&d.MatchNum, &d.MatchID, &d.AutoLineCross, &d.AutoLowBalls, &d.AutoHighBalls, &d.AutoBackBalls, &d.AutoPickups, &d.ShotQuantity, &d.LowFuel, &d.HighFuel, &d.BackFuel, &d.StageOneComplete, &d.StageOneTime, &d.StageTwoComplete, &d.StageTwoTime, &d.Fouls, &d.TechFouls, &d.Card, &d.Climbed, &d.Balanced, &d.ClimbTime, &d.Comments)
+		err = rows.Scan(&d.MatchNum, &d.MatchID, &d.AutoLineCross, &d.AutoLowBalls, &d.AutoHighBalls, &d.AutoBackBalls, &d.AutoPickups, &d.ShotQuantity, &d.LowFuel, &d.HighFuel, &d.BackFuel, &d.StageOneComplete, &d.StageOneTime, &d.StageTwoComplete, &d.StageTwoTime, &d.Fouls, &d.TechFouls, &d.Card, &d.Climbed, &d.Balanced, &d.ClimbTime, &d.DefenseTime, &d.DefenseQuality, &d.ClimbAttempted, &d.Disabled, &d.Tipped, &d.NoShow, &d.Comments, &d.ScoutID, &d.UserID, &d.Ruling)
 		if err != nil {
-			return nil, err
+			return &data, err
 		}
 		d.Team = teamNum
 		data = append(data, d)
@@ -697,85 +1299,65 @@ func GetTeamImages(teamNum int, campaignID string) ([]string, error) {
 GetEventResults gets results from all matches in an event
 */
 func GetEventResults(event string) (*[]MatchData, error) {
-	var matchEvent, competitorid string
 	data := make([]MatchData, 0)
-	rows, err := dbTeams.Query(fmt.Sprintf("SELECT matchid, matchnumber, competitorid, autoLineCross, autoLowBalls, autoHighBalls, autoBackBalls, autoPickups, shotQuantity, lowFuel, highFuel, backFuel, stageOneComplete, stageOneTime, stageTwoComplete, stageTwoTime, fouls, techFouls, card, climbed, balanced, climbtime, comments FROM results WHERE eventid='%s'", event))
+	rows, err := dbTeams.Query(fmt.Sprintf("SELECT results.matchid, results.matchnumber, IFNULL(campaigns.competitors.number, 0), autoLineCross, autoLowBalls, autoHighBalls, autoBackBalls, autoPickups, shotQuantity, lowFuel, highFuel, backFuel, stageOneComplete, stageOneTime, stageTwoComplete, stageTwoTime, fouls, techFouls, card, climbed, balanced, climbtime, defenseTime, defenseQuality, climbAttempted, disabled, tipped, noShow, comments, results.scoutid, results.userid, IFNULL(rulings.ruling, '') FROM results JOIN campaigns.matches ON results.matchid=campaigns.matches.matchid LEFT JOIN campaigns.competitors ON results.competitorid=campaigns.competitors.competitorid LEFT JOIN rulings ON results.scoutid=rulings.scoutid WHERE campaigns.matches.eventid='%s'", event))
+	if err != nil {
+		return &data, err
+	}
 	defer rows.Close()
 	for rows.Next() {
 		var d MatchData
-		err = rows.Scan(&d.MatchID, &d.MatchNum, &competitorid, &d.AutoLineCross, &d.AutoLowBalls, &d.AutoHighBalls, &d.AutoBackBalls, &d.AutoPickups, &d.ShotQuantity, &d.LowFuel, &d.HighFuel, &d.BackFuel, &d.StageOneComplete, &d.StageOneTime, &d.StageTwoComplete, &d.StageTwoTime, &d.Fouls, &d.TechFouls, &d.Card, &d.Climbed, &d.Balanced, &d.ClimbTime, &d.Comments)
+		err = rows.Scan(&d.MatchID, &d.MatchNum, &d.Team, &d.AutoLineCross, &d.AutoLowBalls, &d.AutoHighBalls, &d.AutoBackBalls, &d.AutoPickups, &d.ShotQuantity, &d.LowFuel, &d.HighFuel, &d.BackFuel, &d.StageOneComplete, &d.StageOneTime, &d.StageTwoComplete, &d.StageTwoTime, &d.Fouls, &d.TechFouls, &d.Card, &d.Climbed, &d.Balanced, &d.ClimbTime, &d.DefenseTime, &d.DefenseQuality, &d.ClimbAttempted, &d.Disabled, &d.Tipped, &d.NoShow, &d.Comments, &d.ScoutID, &d.UserID, &d.Ruling)
 		if err != nil {
-			return nil, err
-		}
-		d.Team, _ = GetTeamNumberFromID(competitorid)
-		matchEvent, _ = getMatchEvent(d.MatchID)
-		if matchEvent == event {
-			data = append(data, d)
+			return &data, err
 		}
+		data = append(data, d)
 	}
 	return &data, nil
 }
 
 /*
-GetCurrentEventResults gets results from all matches in an event
+GetCampaignResults gets results from all matches in a campaign
 */
-func GetCurrentEventResults(campaignid string) (*[]MatchData, error) {
-	var matchEvent, competitorid string
-	event, _ := GetActiveCampaignEvent(campaignid)
+func GetCampaignResults(campaignid string) (*[]MatchData, error) {
 	data := make([]MatchData, 0)
-	rows, err := dbTeams.Query(fmt.Sprintf("SELECT matchid, matchnumber, competitorid, autoLineCross, autoLowBalls, autoHighBalls, autoBackBalls, autoPickups, shotQuantity, lowFuel, highFuel, backFuel, stageOneComplete, stageOneTime, stageTwoComplete, stageTwoTime, fouls, techFouls, card, climbed, balanced, climbtime, comments FROM results WHERE eventid='%s'", event))
+	rows, err := dbTeams.Query(fmt.Sprintf("SELECT results.matchid, results.matchnumber, IFNULL(campaigns.competitors.number, 0), autoLineCross, autoLowBalls, autoHighBalls, autoBackBalls, autoPickups, shotQuantity, lowFuel, highFuel, backFuel, stageOneComplete, stageOneTime, stageTwoComplete, stageTwoTime, fouls, techFouls, card, climbed, balanced, climbtime, defenseTime, defenseQuality, climbAttempted, disabled, tipped, noShow, comments, results.scoutid, results.userid, IFNULL(rulings.ruling, '') FROM results JOIN campaigns.matches ON results.matchid=campaigns.matches.matchid LEFT JOIN campaigns.competitors ON results.competitorid=campaigns.competitors.competitorid LEFT JOIN rulings ON results.scoutid=rulings.scoutid WHERE results.campaignid='%s'", campaignid))
+	if err != nil {
+		return &data, err
+	}
 	defer rows.Close()
 	for rows.Next() {
 		var d MatchData
-		err = rows.Scan(&d.MatchID, &d.MatchNum, &competitorid, &d.AutoLineCross, &d.AutoLowBalls, &d.AutoHighBalls, &d.AutoBackBalls, &d.AutoPickups, &d.ShotQuantity, &d.LowFuel, &d.HighFuel, &d.BackFuel, &d.StageOneComplete, &d.StageOneTime, &d.StageTwoComplete, &d.StageTwoTime, &d.Fouls, &d.TechFouls, &d.Card, &d.Climbed, &d.Balanced, &d.ClimbTime, &d.Comments)
+		err = rows.Scan(&d.MatchID, &d.MatchNum, &d.Team, &d.AutoLineCross, &d.AutoLowBalls, &d.AutoHighBalls, &d.AutoBackBalls, &d.AutoPickups, &d.ShotQuantity, &d.LowFuel, &d.HighFuel, &d.BackFuel, &d.StageOneComplete, &d.StageOneTime, &d.StageTwoComplete, &d.StageTwoTime, &d.Fouls, &d.TechFouls, &d.Card, &d.Climbed, &d.Balanced, &d.ClimbTime, &d.DefenseTime, &d.DefenseQuality, &d.ClimbAttempted, &d.Disabled, &d.Tipped, &d.NoShow, &d.Comments, &d.ScoutID, &d.UserID, &d.Ruling)
 		if err != nil {
-			return nil, err
-		}
-		d.Team, _ = GetTeamNumberFromID(competitorid)
-		matchEvent, _ = getMatchEvent(d.MatchID)
-		if matchEvent == event {
-			data = append(data, d)
+			return &data, err
 		}
+		data = append(data, d)
 	}
 	return &data, nil
 }
 
 /*
-GetCampaignResults gets results from all matches in a campaign
+GetPastResults gets a team's results from events in its other campaigns which ran between since and before, in Unix time. Practice scouting in replay sessions is left out.
 */
-func GetCampaignResults(campaignid string) (*[]MatchData, error) {
-	var competitorid string
+func GetPastResults(teamID, campaignID string, since, before int64) (*[]MatchData, error) {
 	data := make([]MatchData, 0)
-	rows, err := dbTeams.Query(fmt.Sprintf("SELECT matchid, matchnumber, competitorid, autoLineCross, autoLowBalls, autoHighBalls, autoBackBalls, autoPickups, shotQuantity, lowFuel, highFuel, backFuel, stageOneComplete, stageOneTime, stageTwoComplete, stageTwoTime, fouls, techFouls, card, climbed, balanced, climbtime, comments FROM results WHERE campaignid='%s'", campaignid))
+	rows, err := dbTeams.Query(fmt.Sprintf("SELECT results.matchid, results.matchnumber, IFNULL(campaigns.competitors.number, 0), autoLineCross, autoLowBalls, autoHighBalls, autoBackBalls, autoPickups, shotQuantity, lowFuel, highFuel, backFuel, stageOneComplete, stageOneTime, stageTwoComplete, stageTwoTime, fouls, techFouls, card, climbed, balanced, climbtime, defenseTime, defenseQuality, climbAttempted, disabled, tipped, noShow, comments, results.scoutid, results.userid, IFNULL(rulings.ruling, '') FROM results JOIN campaigns.matches ON results.matchid=campaigns.matches.matchid JOIN campaigns.events ON campaigns.matches.eventid=campaigns.events.eventid JOIN campaigns.campaigns ON campaigns.events.campaignid=campaigns.campaigns.campaignid LEFT JOIN campaigns.competitors ON results.competitorid=campaigns.competitors.competitorid LEFT JOIN rulings ON results.scoutid=rulings.scoutid WHERE campaigns.campaigns.owner='%s' AND campaigns.events.campaignid!='%s' AND campaigns.events.starttime>='%v' AND campaigns.events.endtime<='%v' AND campaigns.events.campaignid NOT IN ( SELECT campaignid FROM replays )", teamID, campaignID, since, before))
+	if err != nil {
+		return &data, err
+	}
 	defer rows.Close()
 	for rows.Next() {
 		var d MatchData
-		err = rows.Scan(&d.MatchID, &d.MatchNum, &competitorid, &d.AutoLineCross, &d.AutoLowBalls, &d.AutoHighBalls, &d.AutoBackBalls, &d.AutoPickups, &d.ShotQuantity, &d.LowFuel, &d.HighFuel, &d.BackFuel, &d.StageOneComplete, &d.StageOneTime, &d.StageTwoComplete, &d.StageTwoTime, &d.Fouls, &d.TechFouls, &d.Card, &d.Climbed, &d.Balanced, &d.ClimbTime, &d.Comments)
+		err = rows.Scan(&d.MatchID, &d.MatchNum, &d.Team, &d.AutoLineCross, &d.AutoLowBalls, &d.AutoHighBalls, &d.AutoBackBalls, &d.AutoPickups, &d.ShotQuantity, &d.LowFuel, &d.HighFuel, &d.BackFuel, &d.StageOneComplete, &d.StageOneTime, &d.StageTwoComplete, &d.StageTwoTime, &d.Fouls, &d.TechFouls, &d.Card, &d.Climbed, &d.Balanced, &d.ClimbTime, &d.DefenseTime, &d.DefenseQuality, &d.ClimbAttempted, &d.Disabled, &d.Tipped, &d.NoShow, &d.Comments, &d.ScoutID, &d.UserID, &d.Ruling)
 		if err != nil {
-			return nil, err
+			return &data, err
 		}
-		d.Team, _ = GetTeamNumberFromID(competitorid)
 		data = append(data, d)
 	}
 	return &data, nil
 }
 
-/*
-ListMatchIDs gets a list of match ids from a certain campaign
-*/
-func ListMatchIDs(campaignID string) []string {
-	var matchID string
-	matchIDs := make([]string, 0)
-	eventID, _ := GetActiveCampaignEvent(campaignID)
-	rows, _ := dbCampaigns.Query(fmt.Sprintf("SELECT matchid FROM matches WHERE eventid='%s'", eventID))
-	defer rows.Close()
-	for rows.Next() {
-		rows.Scan(&matchID)
-		matchIDs = append(matchIDs, matchID)
-	}
-	return matchIDs
-}
-
 /*
 GetTeamNumberFromID gets a teams number from their competitor id
 */
@@ -794,12 +1376,6 @@ func GetTeamID(number int) (string, error) {
 	return teamID, err
 }
 
-func getMatchEvent(matchID string) (string, error) {
-	var eventID string
-	err := dbCampaigns.QueryRow(fmt.Sprintf("SELECT eventid FROM matches WHERE matchid='%s'", matchID)).Scan(&eventID)
-	return eventID, err
-}
-
 /*
 GetMatchParticipants gets teams participating on each alliance in a match
 */
@@ -811,31 +1387,124 @@ func GetMatchParticipants(matchID string) [][]int {
 }
 
 /*
-GetAllianceParticipants gets teams participating in a particular alliance in a match
+StoreOfficialScore writes an alliance's official score breakdown for a match, replacing any earlier one.
 */
-func GetAllianceParticipants(matchID, alliance string) []int {
-	var teamID string
-	var teamNum int
-	allies := make([]int, 0)
-	rows, _ := dbTeams.Query(fmt.Sprintf("SELECT competitorid FROM results WHERE matchid='%s' AND alliance='%s'", matchID, alliance))
+func StoreOfficialScore(score OfficialScore) error {
+	_, err := dbCampaigns.Exec(fmt.Sprintf("INSERT OR REPLACE INTO officialscores VALUES ( '%s', '%s', '%v', '%v', '%v', '%v', '%v' )", score.MatchID, score.Alliance, score.AutoPoints, score.TeleopCellPoints, score.EndgamePoints, score.FoulPoints, score.TotalPoints))
+	if err != nil {
+		log.Errorf("Unable to write official %s score for match %s: %s", score.Alliance, score.MatchID, err.Error())
+	}
+	return err
+}
+
+/*
+GetOfficialScores gets the official score breakdown of each alliance in a match. Alliances without an official score are left out.
+*/
+func GetOfficialScores(matchID string) ([]OfficialScore, error) {
+	scores := make([]OfficialScore, 0)
+	rows, err := dbCampaigns.Query(fmt.Sprintf("SELECT officialscores.matchid, matches.matchnumber, alliance, autopoints, teleopcellpoints, endgamepoints, foulpoints, totalpoints FROM officialscores JOIN matches ON officialscores.matchid=matches.matchid WHERE officialscores.matchid='%s' ORDER BY alliance DESC", matchID))
+	if err != nil {
+		return scores, err
+	}
 	defer rows.Close()
 	for rows.Next() {
-		rows.Scan(&teamID)
-		teamNum, _ = GetTeamNumberFromID(teamID)
-		if !contains(allies, teamNum) {
-			allies = append(allies, teamNum)
+		var score OfficialScore
+		err = rows.Scan(&score.MatchID, &score.MatchNum, &score.Alliance, &score.AutoPoints, &score.TeleopCellPoints, &score.EndgamePoints, &score.FoulPoints, &score.TotalPoints)
+		if err != nil {
+			return scores, err
 		}
+		scores = append(scores, score)
 	}
-	return allies
+	return scores, nil
+}
+
+/*
+SetMatchParticipant schedules a competitor into a match on an alliance and driver station, replacing its earlier place in the match.
+The competitor is created if it does not exist.
+*/
+func SetMatchParticipant(matchID string, teamNum int, alliance string, station int) error {
+	competitorID := GetCompetitorID(teamNum)
+	if competitorID == "" {
+		CreateCompetitor(teamNum, "")
+		competitorID = GetCompetitorID(teamNum)
+	}
+	_, err := dbCampaigns.Exec(fmt.Sprintf("INSERT OR REPLACE INTO participants VALUES ( '%s', '%s', '%s', '%v' )", matchID, competitorID, alliance, station))
+	if err != nil {
+		log.Errorf("Unable to schedule team %v into match %s: %s", teamNum, matchID, err.Error())
+	}
+	return err
+}
+
+/*
+ClearMatchParticipants removes every competitor scheduled into a match.
+*/
+func ClearMatchParticipants(matchID string) error {
+	_, err := dbCampaigns.Exec(fmt.Sprintf("DELETE FROM participants WHERE matchid='%s'", matchID))
+	return err
 }
 
-func contains(arr []int, val int) bool {
-	for _, x := range arr {
-		if x == val {
-			return true
+/*
+GetEventSchedule gets every qualification match at an event which has scheduled participants, in match order.
+*/
+func GetEventSchedule(eventID string) ([]ScheduledMatch, error) {
+	schedule := make([]ScheduledMatch, 0)
+	rows, err := dbCampaigns.Query(fmt.Sprintf("SELECT matches.matchid, matches.matchnumber, matches.status, participants.alliance, competitors.number FROM matches JOIN participants ON matches.matchid=participants.matchid JOIN competitors ON participants.competitorid=competitors.competitorid WHERE matches.eventid='%s' AND matches.complevel='qm' ORDER BY matches.matchnumber, participants.station", eventID))
+	if err != nil {
+		return schedule, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var matchID, status, alliance string
+		var matchNum, teamNum int
+		err = rows.Scan(&matchID, &matchNum, &status, &alliance, &teamNum)
+		if err != nil {
+			return schedule, err
+		}
+		if len(schedule) == 0 || schedule[len(schedule)-1].MatchID != matchID {
+			schedule = append(schedule, ScheduledMatch{MatchID: matchID, MatchNum: matchNum, Status: status})
+		}
+		match := &schedule[len(schedule)-1]
+		if alliance == "red" {
+			match.Red = append(match.Red, teamNum)
+		} else {
+			match.Blue = append(match.Blue, teamNum)
 		}
 	}
-	return false
+	return schedule, nil
+}
+
+/*
+GetMatchID gets the uuid of a match at an event from its number, creating the match if it does not exist.
+*/
+func GetMatchID(eventID, agentID string, num int) (string, error) {
+	matchID, _ := matchIDFromNum(num, eventID)
+	if matchID != "" {
+		return matchID, nil
+	}
+	err := CreateMatch(eventID, agentID, num, false)
+	if err != nil {
+		return "", err
+	}
+	return matchIDFromNum(num, eventID)
+}
+
+/*
+GetAllianceParticipants gets teams participating in a particular alliance in a match
+*/
+func GetAllianceParticipants(matchID, alliance string) []int {
+	var teamNum int
+	allies := make([]int, 0)
+	rows, err := dbTeams.Query(fmt.Sprintf("SELECT campaigns.competitors.number FROM results JOIN campaigns.competitors ON results.competitorid=campaigns.competitors.competitorid WHERE results.matchid='%s' AND results.alliance='%s' GROUP BY campaigns.competitors.number ORDER BY MIN(results.rowid)", matchID, alliance))
+	if err != nil {
+		log.Warnf("Unable to get %s alliance for match %s: %s", alliance, matchID, err.Error())
+		return allies
+	}
+	defer rows.Close()
+	for rows.Next() {
+		rows.Scan(&teamNum)
+		allies = append(allies, teamNum)
+	}
+	return allies
 }
 
 /*
@@ -862,134 +1531,946 @@ TEAM ADMIN FUNCTIONS
 */
 
 /*
-CAMPAIGN FUCTIONS
+SetResultRuling records a supervisor's ruling on a results entry, replacing any earlier ruling on it.
+Marking an entry authoritative clears the authoritative ruling from any other entry for the same robot in the same match.
 */
+func SetResultRuling(scoutID, userID, ruling string) error {
+	if ruling != RulingAuthoritative && ruling != RulingExcluded {
+		return fmt.Errorf("unknown ruling %q", ruling)
+	}
+	var matchID, competitorID string
+	err := dbTeams.QueryRow(fmt.Sprintf("SELECT matchid, competitorid FROM results WHERE scoutid='%s'", scoutID)).Scan(&matchID, &competitorID)
+	if err != nil {
+		log.Warnf("Unable to find results entry %s: %s", scoutID, err.Error())
+		return err
+	}
+	if ruling == RulingAuthoritative {
+		_, err = dbTeams.Exec(fmt.Sprintf("DELETE FROM rulings WHERE ruling='%s' AND scoutid IN ( SELECT scoutid FROM results WHERE matchid='%s' AND competitorid='%s' )", RulingAuthoritative, matchID, competitorID))
+		if err != nil {
+			log.Errorf("Unable to clear rulings for match %s: %s", matchID, err.Error())
+			return err
+		}
+	}
+	_, err = dbTeams.Exec(fmt.Sprintf("INSERT OR REPLACE INTO rulings VALUES ( '%s', '%s', '%s', '%s' )", scoutID, ruling, userID, time.Now().Format("2006-01-02 15:04:05")))
+	if err != nil {
+		log.Errorf("Unable to write ruling on results entry %s: %s", scoutID, err.Error())
+		return err
+	}
+	log.Infof("User %s marked results entry %s %s.", userID, scoutID, ruling)
+	resultEntryChanged(scoutID)
+	return nil
+}
 
 /*
-GetActiveCampaignEvent gets the eventid of the active event in the given campaign
+VoidMatchResults rules every results entry already submitted for a match excluded, for when the match is replayed and its first run no longer counts.
 */
-func GetActiveCampaignEvent(campaignid string) (string, error) {
-	var eventid, cid string
-	var starttime, endtime int64
-	rows, err := dbCampaigns.Query("SELECT eventid, campaignid, starttime, endtime FROM events")
-	defer rows.Close()
-	//checks if event is currently taking place
+func VoidMatchResults(matchID, userID string) error {
+	var scoutID string
+	scoutIDs := make([]string, 0)
+	rows, err := dbTeams.Query(fmt.Sprintf("SELECT scoutid FROM results WHERE matchid='%s'", matchID))
+	if err != nil {
+		log.Errorf("Unable to find results for match %s: %s", matchID, err.Error())
+		return err
+	}
 	for rows.Next() {
-		err = rows.Scan(&eventid, &cid, &starttime, &endtime)
+		rows.Scan(&scoutID)
+		scoutIDs = append(scoutIDs, scoutID)
+	}
+	rows.Close()
+	for _, scoutID := range scoutIDs {
+		err = SetResultRuling(scoutID, userID, RulingExcluded)
 		if err != nil {
-			return "", err
-		}
-		if starttime < time.Now().Unix() && endtime > time.Now().Unix() {
-			break
+			return err
 		}
 	}
-	return eventid, nil
+	return nil
 }
 
 /*
-GetEventMatchIDs gets a list of matchids from a given event
+ClearResultRuling removes a supervisor's ruling from a results entry.
 */
-func GetEventMatchIDs(eventid string) []string {
-	var matchid string
-	matchids := make([]string, 0)
-	rows, _ := dbCampaigns.Query(fmt.Sprintf("SELECT matchid FROM matches WHERE eventid='%s'", eventid))
-	defer rows.Close()
-	for rows.Next() {
-		rows.Scan(&matchid)
-		matchids = append(matchids, matchid)
+func ClearResultRuling(scoutID string) error {
+	_, err := dbTeams.Exec(fmt.Sprintf("DELETE FROM rulings WHERE scoutid='%s'", scoutID))
+	if err == nil {
+		resultEntryChanged(scoutID)
 	}
-	return matchids
+	return err
 }
 
 /*
-CampaignCreate TODO.
+GetPickList gets a team's pick list for an event, in order.
 */
-func CampaignCreate(agentid, owner, name string) {
-	// TODO: Clone global campaigns to team-specific campaign if requested.
-	// Only sysadmin can create global campaigns.
-	uuid := uuid.New().String()
-	dbCampaigns.Exec(fmt.Sprintf("INSERT INTO campaigns VALUES ( '%s', '%s', '%s' )", uuid, owner, name))
+func GetPickList(teamID, eventID, listType string) ([]PickListEntry, error) {
+	list := make([]PickListEntry, 0)
+	rows, err := dbTeams.Query(fmt.Sprintf("SELECT number, struck FROM picklists WHERE teamid='%s' AND eventid='%s' AND listtype='%s' ORDER BY position", teamID, eventID, listType))
+	if err != nil {
+		return list, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var entry PickListEntry
+		err = rows.Scan(&entry.Number, &entry.Struck)
+		if err != nil {
+			return list, err
+		}
+		list = append(list, entry)
+	}
+	return list, nil
 }
 
 /*
-CampaignClone TODO
+WritePickList replaces a team's pick list for an event and records the change in the pick list's history.
 */
-func CampaignClone(agentID, clonedID, teamID string) {
-
+func WritePickList(teamID, eventID, listType, userID, action string, list []PickListEntry) error {
+	if listType != PickListFirst && listType != PickListSecond {
+		return fmt.Errorf("unknown pick list %q", listType)
+	}
+	tx, err := dbTeams.Begin()
+	if err != nil {
+		return err
+	}
+	_, err = tx.Exec(fmt.Sprintf("DELETE FROM picklists WHERE teamid='%s' AND eventid='%s' AND listtype='%s'", teamID, eventID, listType))
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	for position, entry := range list {
+		_, err = tx.Exec(fmt.Sprintf("INSERT INTO picklists VALUES ( '%s', '%s', '%s', '%v', '%v', '%v' )", teamID, eventID, listType, position, entry.Number, entry.Struck))
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+	_, err = tx.Exec(fmt.Sprintf("INSERT INTO picklisthistory VALUES ( '%s', '%s', '%s', '%s', '%s', '%s', '%s', '%s' )", uuid.New().String(), teamID, eventID, listType, userID, time.Now().Format("2006-01-02 15:04:05"), escapeText(action), encodePickList(list)))
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	err = tx.Commit()
+	if err != nil {
+		log.Errorf("Unable to write %s pick list for team %s: %s", listType, teamID, err.Error())
+		return err
+	}
+	log.Debugf("User %s changed the %s pick list for team %s: %s", userID, listType, teamID, action)
+	return nil
 }
 
 /*
-CampaignList returns list of campaigns as id: owner, name
+GetPickListHistory gets every change made to a team's pick lists for an event, newest first.
 */
-func CampaignList() map[string][]string {
-	rows, err := dbCampaigns.Query("SELECT campaignid, owner, name FROM campaigns")
+func GetPickListHistory(teamID, eventID string) ([]PickListChange, error) {
+	history := make([]PickListChange, 0)
+	rows, err := dbTeams.Query(fmt.Sprintf("SELECT listtype, userid, time, action, list FROM picklisthistory WHERE teamid='%s' AND eventid='%s' ORDER BY time DESC", teamID, eventID))
+	if err != nil {
+		return history, err
+	}
 	defer rows.Close()
-	accessCheck(err)
-	results := make(map[string][]string)
-	var id, owner, name string
 	for rows.Next() {
-		rows.Scan(&id, &owner, &name)
-		results[id] = append(results[id], owner, name)
+		var change PickListChange
+		var list string
+		err = rows.Scan(&change.ListType, &change.UserID, &change.Time, &change.Action, &list)
+		if err != nil {
+			return history, err
+		}
+		change.Action = unescapeText(change.Action)
+		change.List = decodePickList(list)
+		history = append(history, change)
 	}
-	return results
+	return history, nil
 }
 
 /*
-CreateEvent adds an event to the event table in the campaigns database
-Its starttime and endtime should be Unix time integers of its start and end dates
+encodePickList serializes a pick list as comma separated team numbers. Struck teams are followed by an asterisk.
 */
-func CreateEvent(campaignid, agentid, name, location string, starttime, endtime int) error {
-	eventid := uuid.New().String()
-	dbCampaigns.Exec(fmt.Sprintf("INSERT INTO events VALUES ( '%s', '%s', '%s', '%s', '%v', '%v')", eventid, campaignid, name, location, starttime, endtime))
-	return nil
+func encodePickList(list []PickListEntry) string {
+	var build strings.Builder
+	for ind, entry := range list {
+		build.WriteString(strconv.Itoa(entry.Number))
+		if entry.Struck {
+			build.WriteString("*")
+		}
+		if ind != len(list)-1 {
+			build.WriteString(",")
+		}
+	}
+	return build.String()
 }
 
 /*
-CreateMatch adds a mach to the match table in the campaign database
+decodePickList deserializes a pick list written by encodePickList.
 */
-func CreateMatch(eventid, agentid string, num int, active bool) error {
-	matchid := uuid.New().String()
-	_, err := dbCampaigns.Exec(fmt.Sprintf("INSERT INTO matches VALUES ( '%s', '%s', '%v', '%v' )", matchid, eventid, num, active))
-	if err == nil {
-		log.Infof("Created match #%v for event %s", num, eventid)
+func decodePickList(str string) []PickListEntry {
+	list := make([]PickListEntry, 0)
+	for _, field := range strings.Split(str, ",") {
+		var entry PickListEntry
+		entry.Struck = strings.HasSuffix(field, "*")
+		number, err := strconv.Atoi(strings.TrimSuffix(field, "*"))
+		if err != nil {
+			continue
+		}
+		entry.Number = number
+		list = append(list, entry)
 	}
-	return err
+	return list
 }
 
 /*
-TEAM MATCH DATA READ-WRITE FUNCTIONS
+GetCompetitorPriorities gets how much a team wants each competitor at an event scouted, by team number. Competitors without a priority are left out.
 */
-
-func getCampaignOwner(campaignid string, db *sql.DB) string {
-	// TODO
-	return ""
+func GetCompetitorPriorities(teamID, eventID string) (map[int]int, error) {
+	var number, priority int
+	priorities := make(map[int]int)
+	rows, err := dbTeams.Query(fmt.Sprintf("SELECT campaigns.competitors.number, priority FROM priorities JOIN campaigns.competitors ON priorities.competitorid=campaigns.competitors.competitorid WHERE teamid='%s' AND eventid='%s'", teamID, eventID))
+	if err != nil {
+		return priorities, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		err = rows.Scan(&number, &priority)
+		if err != nil {
+			return priorities, err
+		}
+		priorities[number] = priority
+	}
+	return priorities, nil
 }
 
-func retconCompetitorID(teamnumber, teamid, DatabasePath string) {
-	// TODO
+/*
+SetCompetitorPriority sets how much a team wants a competitor at an event scouted, creating the competitor if it doesn't exist.
+*/
+func SetCompetitorPriority(teamID, eventID string, teamNum, priority int) error {
+	competitorID := GetCompetitorID(teamNum)
+	if competitorID == "" {
+		CreateCompetitor(teamNum, "")
+		competitorID = GetCompetitorID(teamNum)
+	}
+	_, err := dbTeams.Exec(fmt.Sprintf("INSERT OR REPLACE INTO priorities VALUES ( '%s', '%s', '%s', '%v' )", teamID, eventID, competitorID, priority))
+	if err != nil {
+		log.Errorf("Unable to set priority of competitor %v for team %s: %s", teamNum, teamID, err.Error())
+	}
+	return err
 }
 
 /*
-ResultsWrite writes
+GetMatchAssignments gets which competitor, by team number, each of a team's scouts is assigned to in a match.
 */
-func ResultsWrite(useriD, teamID string, data MatchData) {
-	// TODO
-	// Throw if overwriting existing
+func GetMatchAssignments(teamID, matchID string) (map[string]int, error) {
+	var userID string
+	var number int
+	assignments := make(map[string]int)
+	rows, err := dbTeams.Query(fmt.Sprintf("SELECT userid, campaigns.competitors.number FROM assignments JOIN campaigns.competitors ON assignments.competitorid=campaigns.competitors.competitorid WHERE teamid='%s' AND matchid='%s'", teamID, matchID))
+	if err != nil {
+		return assignments, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		err = rows.Scan(&userID, &number)
+		if err != nil {
+			return assignments, err
+		}
+		assignments[userID] = number
+	}
+	return assignments, nil
 }
 
 /*
-ResultsRead reads match results
+GetScoutAssignments gets every assignment of a team's scouts in a match, in the order they were made.
 */
-func ResultsRead() {
+func GetScoutAssignments(teamID, matchID string) ([]ScoutAssignment, error) {
+	var opened string
+	assignments := make([]ScoutAssignment, 0)
+	rows, err := dbTeams.Query(fmt.Sprintf("SELECT userid, campaigns.competitors.number, opened FROM assignments JOIN campaigns.competitors ON assignments.competitorid=campaigns.competitors.competitorid WHERE teamid='%s' AND matchid='%s' ORDER BY assignments.rowid", teamID, matchID))
+	if err != nil {
+		return assignments, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var assignment ScoutAssignment
+		err = rows.Scan(&assignment.UserID, &assignment.Team, &opened)
+		if err != nil {
+			return assignments, err
+		}
+		assignment.Opened = opened != ""
+		assignments = append(assignments, assignment)
+	}
+	return assignments, nil
+}
 
+/*
+OpenAssignment records that a scout has opened the scouting form for their assignment in a match. Only the first time is kept.
+*/
+func OpenAssignment(teamID, matchID, userID string) error {
+	_, err := dbTeams.Exec(fmt.Sprintf("UPDATE assignments SET opened='%s' WHERE teamid='%s' AND matchid='%s' AND userid='%s' AND opened=''", time.Now().Format("2006-01-02 15:04:05"), teamID, matchID, userID))
+	return err
 }
 
 /*
-CreateCompetitor creates a competitor
+WriteAssignment assigns one of a team's scouts to a competitor in a match, replacing any earlier assignment for that scout in the match.
 */
-func CreateCompetitor(teamNumber int, name string) {
-	competitorID := uuid.New().String()
-	dbCampaigns.Exec(fmt.Sprintf("INSERT INTO competitors VALUES ( '%s', '%v', '%s' )", competitorID, teamNumber, name))
+func WriteAssignment(teamID, matchID, userID string, teamNum int) error {
+	competitorID := GetCompetitorID(teamNum)
+	if competitorID == "" {
+		CreateCompetitor(teamNum, "")
+		competitorID = GetCompetitorID(teamNum)
+	}
+	_, err := dbTeams.Exec(fmt.Sprintf("INSERT OR REPLACE INTO assignments ( teamid, matchid, userid, competitorid, time ) VALUES ( '%s', '%s', '%s', '%s', '%s' )", teamID, matchID, userID, competitorID, time.Now().Format("2006-01-02 15:04:05")))
+	if err != nil {
+		log.Errorf("Unable to assign user %s to competitor %v in match %s: %s", userID, teamNum, matchID, err.Error())
+	}
+	return err
+}
+
+/*
+ClearMatchAssignments removes all of a team's scout assignments for a match.
+*/
+func ClearMatchAssignments(teamID, matchID string) error {
+	_, err := dbTeams.Exec(fmt.Sprintf("DELETE FROM assignments WHERE teamid='%s' AND matchid='%s'", teamID, matchID))
+	return err
+}
+
+/*
+GetShiftRules gets the rules a team's scout shift schedule is generated from, or DefaultShiftRules if the team hasn't set any.
+*/
+func GetShiftRules(teamID string) (ShiftRules, error) {
+	var pitCrew string
+	rules := DefaultShiftRules
+	err := dbTeams.QueryRow(fmt.Sprintf("SELECT scoutspermatch, maxconsecutive, breaklength, pitcrew FROM shiftrules WHERE teamid='%s'", teamID)).Scan(&rules.ScoutsPerMatch, &rules.MaxConsecutive, &rules.BreakLength, &pitCrew)
+	if err == sql.ErrNoRows {
+		return DefaultShiftRules, nil
+	}
+	if err != nil {
+		return DefaultShiftRules, err
+	}
+	rules.PitCrew = make([]string, 0)
+	for _, userID := range strings.Split(pitCrew, ",") {
+		if userID != "" {
+			rules.PitCrew = append(rules.PitCrew, userID)
+		}
+	}
+	return rules, nil
+}
+
+/*
+SetShiftRules sets the rules a team's scout shift schedule is generated from.
+*/
+func SetShiftRules(teamID string, rules ShiftRules) error {
+	_, err := dbTeams.Exec(fmt.Sprintf("INSERT OR REPLACE INTO shiftrules VALUES ( '%s', '%v', '%v', '%v', '%s' )", teamID, rules.ScoutsPerMatch, rules.MaxConsecutive, rules.BreakLength, strings.Join(rules.PitCrew, ",")))
+	if err != nil {
+		log.Errorf("Unable to write shift rules for team %s: %s", teamID, err.Error())
+	}
+	return err
+}
+
+/*
+GetShifts gets which of a team's scouts are on shift for each match at an event, by matchid.
+*/
+func GetShifts(teamID, eventID string) (map[string][]string, error) {
+	var matchID, userID string
+	shifts := make(map[string][]string)
+	rows, err := dbTeams.Query(fmt.Sprintf("SELECT matchid, userid FROM shifts WHERE teamid='%s' AND eventid='%s' ORDER BY rowid", teamID, eventID))
+	if err != nil {
+		return shifts, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		err = rows.Scan(&matchID, &userID)
+		if err != nil {
+			return shifts, err
+		}
+		shifts[matchID] = append(shifts[matchID], userID)
+	}
+	return shifts, nil
+}
+
+/*
+WriteShifts replaces a team's whole shift schedule for an event. Shifts are keyed by matchid.
+*/
+func WriteShifts(teamID, eventID string, shifts map[string][]string) error {
+	tx, err := dbTeams.Begin()
+	if err != nil {
+		return err
+	}
+	_, err = tx.Exec(fmt.Sprintf("DELETE FROM shifts WHERE teamid='%s' AND eventid='%s'", teamID, eventID))
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	for matchID, userIDs := range shifts {
+		for _, userID := range userIDs {
+			_, err = tx.Exec(fmt.Sprintf("INSERT OR IGNORE INTO shifts VALUES ( '%s', '%s', '%s', '%s' )", teamID, eventID, matchID, userID))
+			if err != nil {
+				tx.Rollback()
+				log.Errorf("Unable to write shift schedule for team %s: %s", teamID, err.Error())
+				return err
+			}
+		}
+	}
+	return tx.Commit()
+}
+
+/*
+SetShift puts one of a team's scouts on or off shift for a match.
+*/
+func SetShift(teamID, eventID, matchID, userID string, onShift bool) error {
+	var err error
+	if onShift {
+		_, err = dbTeams.Exec(fmt.Sprintf("INSERT OR IGNORE INTO shifts VALUES ( '%s', '%s', '%s', '%s' )", teamID, eventID, matchID, userID))
+	} else {
+		_, err = dbTeams.Exec(fmt.Sprintf("DELETE FROM shifts WHERE teamid='%s' AND matchid='%s' AND userid='%s'", teamID, matchID, userID))
+	}
+	return err
+}
+
+/*
+GetMatchSubmissions gets which competitor, by team number, each scout in a campaign has submitted results for in a match.
+*/
+func GetMatchSubmissions(campaignID, matchID string) (map[string][]int, error) {
+	var userID string
+	var number int
+	submissions := make(map[string][]int)
+	rows, err := dbTeams.Query(fmt.Sprintf("SELECT results.userid, IFNULL(campaigns.competitors.number, 0) FROM results LEFT JOIN campaigns.competitors ON results.competitorid=campaigns.competitors.competitorid WHERE results.campaignid='%s' AND results.matchid='%s'", campaignID, matchID))
+	if err != nil {
+		return submissions, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		err = rows.Scan(&userID, &number)
+		if err != nil {
+			return submissions, err
+		}
+		submissions[userID] = append(submissions[userID], number)
+	}
+	return submissions, nil
+}
+
+/*
+GetScoutedMatches gets which matches at an event any scout in a campaign has submitted results for. Results which were ruled excluded, such as those from before a match was replayed, don't count.
+*/
+func GetScoutedMatches(campaignID, eventID string) (map[string]bool, error) {
+	var matchID string
+	scouted := make(map[string]bool)
+	rows, err := dbTeams.Query(fmt.Sprintf("SELECT DISTINCT results.matchid FROM results LEFT JOIN rulings ON results.scoutid=rulings.scoutid WHERE campaignid='%s' AND eventid='%s' AND IFNULL(rulings.ruling, '')!='%s'", campaignID, eventID, RulingExcluded))
+	if err != nil {
+		return scouted, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		rows.Scan(&matchID)
+		scouted[matchID] = true
+	}
+	return scouted, nil
+}
+
+/*
+GetCurrentMatch gets the match a team's admins have said is being played now at an event. If they haven't, the match most recently made current at the event by any team is used instead. Returns sql.ErrNoRows if there is neither.
+*/
+func GetCurrentMatch(teamID, eventID string) (string, error) {
+	var matchID string
+	err := dbTeams.QueryRow(fmt.Sprintf("SELECT schedules.matchid FROM schedules JOIN campaigns.matches ON schedules.matchid=campaigns.matches.matchid WHERE teamid='%s' AND campaigns.matches.eventid='%s'", teamID, eventID)).Scan(&matchID)
+	if err != sql.ErrNoRows {
+		return matchID, err
+	}
+	err = dbCampaigns.QueryRow(fmt.Sprintf("SELECT matchid FROM matches WHERE eventid='%s' AND active=1 ORDER BY matchnumber LIMIT 1", eventID)).Scan(&matchID)
+	return matchID, err
+}
+
+/*
+SetCurrentMatch sets the match being played now at a team's active event, and marks it as the event's active match.
+*/
+func SetCurrentMatch(teamID, eventID, matchID string) error {
+	_, err := dbTeams.Exec(fmt.Sprintf("UPDATE schedules SET matchid='%s' WHERE teamid='%s'", matchID, teamID))
+	if err != nil {
+		log.Errorf("Unable to set current match for team %s: %s", teamID, err.Error())
+		return err
+	}
+	_, err = dbCampaigns.Exec(fmt.Sprintf("UPDATE matches SET active=(matchid='%s') WHERE eventid='%s'", matchID, eventID))
+	if err != nil {
+		log.Errorf("Unable to mark match %s active: %s", matchID, err.Error())
+	}
+	return err
+}
+
+/*
+SetMatchStatus sets how a match has strayed from the schedule. An empty status puts it back on schedule.
+*/
+func SetMatchStatus(matchID, status string) error {
+	_, err := dbCampaigns.Exec(fmt.Sprintf("UPDATE matches SET status='%s' WHERE matchid='%s'", status, matchID))
+	if err != nil {
+		log.Errorf("Unable to set status of match %s: %s", matchID, err.Error())
+	}
+	return err
+}
+
+/*
+CAMPAIGN FUCTIONS
+*/
+
+/*
+GetActiveCampaignEvent gets the eventid of the active event in the given campaign: the one taking place now, or otherwise the one which starts last.
+Returns sql.ErrNoRows if the campaign has no events.
+*/
+func GetActiveCampaignEvent(campaignid string) (string, error) {
+	var eventid, latest string
+	var starttime, endtime int64
+	rows, err := dbCampaigns.Query(fmt.Sprintf("SELECT eventid, starttime, endtime FROM events WHERE campaignid='%s' ORDER BY starttime DESC, rowid DESC", campaignid))
+	if err != nil {
+		return "", err
+	}
+	defer rows.Close()
+	now := time.Now().Unix()
+	//checks if event is currently taking place
+	for rows.Next() {
+		err = rows.Scan(&eventid, &starttime, &endtime)
+		if err != nil {
+			return "", err
+		}
+		if starttime <= now && endtime > now {
+			return eventid, nil
+		}
+		if latest == "" {
+			latest = eventid
+		}
+	}
+	if latest == "" {
+		return "", sql.ErrNoRows
+	}
+	return latest, nil
+}
+
+/*
+GetEventMatchIDs gets a list of matchids from a given event
+*/
+func GetEventMatchIDs(eventid string) []string {
+	var matchid string
+	matchids := make([]string, 0)
+	rows, _ := dbCampaigns.Query(fmt.Sprintf("SELECT matchid FROM matches WHERE eventid='%s'", eventid))
+	defer rows.Close()
+	for rows.Next() {
+		rows.Scan(&matchid)
+		matchids = append(matchids, matchid)
+	}
+	return matchids
+}
+
+/*
+CampaignCreate TODO.
+*/
+func CampaignCreate(agentid, owner, name string) {
+	// TODO: Clone global campaigns to team-specific campaign if requested.
+	// Only sysadmin can create global campaigns.
+	uuid := uuid.New().String()
+	dbCampaigns.Exec(fmt.Sprintf("INSERT INTO campaigns VALUES ( '%s', '%s', '%s' )", uuid, owner, name))
+}
+
+/*
+CreateSandboxCampaign creates a campaign owned by a team for practice scouting, and returns its uuid. It is marked as a sandbox, and hidden from CampaignList, once a replay session is started in it. See StartReplaySession
+*/
+func CreateSandboxCampaign(teamID, name string) (string, error) {
+	campaignID := uuid.New().String()
+	_, err := dbCampaigns.Exec(fmt.Sprintf("INSERT INTO campaigns VALUES ( '%s', '%s', '%s' )", campaignID, teamID, escapeText(name)))
+	if err != nil {
+		log.Errorf("Unable to create sandbox campaign for team %s: %s", teamID, err.Error())
+		return "", err
+	}
+	return campaignID, nil
+}
+
+/*
+CampaignClone TODO
+*/
+func CampaignClone(agentID, clonedID, teamID string) {
+
+}
+
+/*
+DeleteSandboxCampaign deletes a sandbox campaign along with its events, their matches and everything scouted or planned for them. It must only be given sandbox campaigns.
+*/
+func DeleteSandboxCampaign(campaignID string) error {
+	events := fmt.Sprintf("SELECT eventid FROM campaigns.events WHERE campaignid='%s'", campaignID)
+	matches := fmt.Sprintf("SELECT matchid FROM campaigns.matches WHERE eventid IN ( %s )", events)
+	queries := []string{
+		fmt.Sprintf("DELETE FROM rulings WHERE scoutid IN ( SELECT scoutid FROM results WHERE matchid IN ( %s ) )", matches),
+		fmt.Sprintf("DELETE FROM results WHERE matchid IN ( %s )", matches),
+		fmt.Sprintf("DELETE FROM assignments WHERE matchid IN ( %s )", matches),
+		fmt.Sprintf("DELETE FROM campaigns.participants WHERE matchid IN ( %s )", matches),
+		fmt.Sprintf("DELETE FROM campaigns.officialscores WHERE matchid IN ( %s )", matches),
+	}
+	for _, table := range []string{"shifts", "pitplans", "picklists", "picklisthistory", "priorities", "campaigns.rankings", "campaigns.priors", "campaigns.eventteams", "campaigns.matches"} {
+		queries = append(queries, fmt.Sprintf("DELETE FROM %s WHERE eventid IN ( %s )", table, events))
+	}
+	for _, table := range []string{"campaigns.pitscout", "campaigns.images", "campaigns.events", "campaigns.campaigns"} {
+		queries = append(queries, fmt.Sprintf("DELETE FROM %s WHERE campaignid='%s'", table, campaignID))
+	}
+	tx, err := dbTeams.Begin()
+	if err != nil {
+		return err
+	}
+	for _, query := range queries {
+		_, err = tx.Exec(query)
+		if err != nil {
+			tx.Rollback()
+			log.Errorf("Unable to delete sandbox campaign %s: %s", campaignID, err.Error())
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+/*
+CampaignList returns list of campaigns as id: owner, name. Sandbox campaigns made for practice scouting sessions are left out.
+*/
+func CampaignList() map[string][]string {
+	rows, err := dbTeams.Query("SELECT campaignid, owner, name FROM campaigns.campaigns WHERE campaignid NOT IN ( SELECT campaignid FROM replays )")
+	defer rows.Close()
+	accessCheck(err)
+	results := make(map[string][]string)
+	var id, owner, name string
+	for rows.Next() {
+		rows.Scan(&id, &owner, &name)
+		results[id] = append(results[id], owner, unescapeText(name))
+	}
+	return results
+}
+
+/*
+GetCampaignEvents gets the name of every event in a campaign, by eventid.
+*/
+func GetCampaignEvents(campaignID string) (map[string]string, error) {
+	var eventID, name string
+	events := make(map[string]string)
+	rows, err := dbCampaigns.Query(fmt.Sprintf("SELECT eventid, name FROM events WHERE campaignid='%s'", campaignID))
+	if err != nil {
+		return events, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		rows.Scan(&eventID, &name)
+		events[eventID] = unescapeText(name)
+	}
+	return events, nil
+}
+
+/*
+StoreEvent adds an event imported from The Blue Alliance to a campaign, or updates it if it was imported into the campaign before, and returns its eventid.
+An empty tbaKey always adds a new event, which is never synced with The Blue Alliance.
+Its starttime and endtime should be Unix time integers of its start and end dates
+*/
+func StoreEvent(campaignID, tbaKey, name, location string, starttime, endtime int64) (string, error) {
+	var eventID string
+	err := sql.ErrNoRows
+	if tbaKey != "" {
+		err = dbCampaigns.QueryRow(fmt.Sprintf("SELECT eventid FROM events WHERE campaignid='%s' AND tbakey='%s'", campaignID, tbaKey)).Scan(&eventID)
+	}
+	if err == sql.ErrNoRows {
+		eventID = uuid.New().String()
+		_, err = dbCampaigns.Exec(fmt.Sprintf("INSERT INTO events ( eventid, campaignid, name, location, starttime, endtime, tbakey ) VALUES ( '%s', '%s', '%s', '%s', '%v', '%v', '%s' )", eventID, campaignID, escapeText(name), escapeText(location), starttime, endtime, tbaKey))
+	} else if err == nil {
+		_, err = dbCampaigns.Exec(fmt.Sprintf("UPDATE events SET name='%s', location='%s', starttime='%v', endtime='%v' WHERE eventid='%s'", escapeText(name), escapeText(location), starttime, endtime, eventID))
+	}
+	if err != nil {
+		log.Errorf("Unable to store event %s: %s", tbaKey, err.Error())
+		return "", err
+	}
+	return eventID, nil
+}
+
+/*
+StoreImportedMatch adds a match imported from The Blue Alliance to an event, or updates it if it is already there, and replaces its participants.
+A qualification match made by a scout's submission before the import is taken over rather than duplicated, so the results already scouted for it stay with it.
+*/
+func StoreImportedMatch(eventID string, match ImportedMatch) (string, error) {
+	var matchID string
+	err := dbCampaigns.QueryRow(fmt.Sprintf("SELECT matchid FROM matches WHERE eventid='%s' AND tbakey='%s'", eventID, match.Key)).Scan(&matchID)
+	if err == sql.ErrNoRows && match.CompLevel == "qm" {
+		err = dbCampaigns.QueryRow(fmt.Sprintf("SELECT matchid FROM matches WHERE eventid='%s' AND complevel='qm' AND matchnumber='%v' AND tbakey=''", eventID, match.MatchNum)).Scan(&matchID)
+	}
+	if err == sql.ErrNoRows {
+		matchID = uuid.New().String()
+		_, err = dbCampaigns.Exec(fmt.Sprintf("INSERT INTO matches ( matchid, eventid, matchnumber, active, complevel, setnumber, time, tbakey ) VALUES ( '%s', '%s', '%v', 0, '%s', '%v', '%v', '%s' )", matchID, eventID, match.MatchNum, match.CompLevel, match.SetNumber, match.Time, match.Key))
+	} else if err == nil {
+		_, err = dbCampaigns.Exec(fmt.Sprintf("UPDATE matches SET matchnumber='%v', complevel='%s', setnumber='%v', time='%v', tbakey='%s' WHERE matchid='%s'", match.MatchNum, match.CompLevel, match.SetNumber, match.Time, match.Key, matchID))
+	}
+	if err != nil {
+		log.Errorf("Unable to store match %s: %s", match.Key, err.Error())
+		return "", err
+	}
+	err = ClearMatchParticipants(matchID)
+	if err != nil {
+		return matchID, err
+	}
+	for alliance, teams := range map[string][]int{"red": match.Red, "blue": match.Blue} {
+		for ind, teamNum := range teams {
+			err = SetMatchParticipant(matchID, teamNum, alliance, ind+1)
+			if err != nil {
+				return matchID, err
+			}
+		}
+	}
+	return matchID, nil
+}
+
+/*
+GetTBAEvents gets every event imported from The Blue Alliance under an event key, as eventid: campaignid. The same event can be imported into more than one campaign.
+*/
+func GetTBAEvents(tbaKey string) (map[string]string, error) {
+	var eventID, campaignID string
+	events := make(map[string]string)
+	rows, err := dbCampaigns.Query(fmt.Sprintf("SELECT eventid, campaignid FROM events WHERE tbakey='%s'", tbaKey))
+	if err != nil {
+		return events, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		rows.Scan(&eventID, &campaignID)
+		events[eventID] = campaignID
+	}
+	return events, nil
+}
+
+/*
+GetEventTBAKey gets The Blue Alliance's key for an event, which is empty unless the event was imported from there.
+*/
+func GetEventTBAKey(eventID string) (string, error) {
+	var tbaKey string
+	err := dbCampaigns.QueryRow(fmt.Sprintf("SELECT tbakey FROM events WHERE eventid='%s'", eventID)).Scan(&tbaKey)
+	return tbaKey, err
+}
+
+/*
+GetEventTimes gets when an event starts and ends, in Unix time.
+*/
+func GetEventTimes(eventID string) (int64, int64, error) {
+	var starttime, endtime int64
+	err := dbCampaigns.QueryRow(fmt.Sprintf("SELECT starttime, endtime FROM events WHERE eventid='%s'", eventID)).Scan(&starttime, &endtime)
+	return starttime, endtime, err
+}
+
+/*
+StoreRankings replaces an event's official qualification rankings.
+*/
+func StoreRankings(eventID string, rankings []Ranking) error {
+	for _, ranking := range rankings {
+		if GetCompetitorID(ranking.Team) == "" {
+			CreateCompetitor(ranking.Team, "")
+		}
+	}
+	tx, err := dbCampaigns.Begin()
+	if err != nil {
+		return err
+	}
+	_, err = tx.Exec(fmt.Sprintf("DELETE FROM rankings WHERE eventid='%s'", eventID))
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	for _, ranking := range rankings {
+		_, err = tx.Exec(fmt.Sprintf("INSERT INTO rankings VALUES ( '%s', '%s', '%v', '%v', '%v', '%v', '%v', '%v' )", eventID, GetCompetitorID(ranking.Team), ranking.Rank, ranking.Wins, ranking.Losses, ranking.Ties, ranking.Played, ranking.RankingScore))
+		if err != nil {
+			tx.Rollback()
+			log.Errorf("Unable to store rankings for event %s: %s", eventID, err.Error())
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+/*
+GetRankings gets an event's official qualification rankings, best first.
+*/
+func GetRankings(eventID string) ([]Ranking, error) {
+	rankings := make([]Ranking, 0)
+	rows, err := dbCampaigns.Query(fmt.Sprintf("SELECT competitors.number, rank, wins, losses, ties, played, rankingscore FROM rankings JOIN competitors ON rankings.competitorid=competitors.competitorid WHERE eventid='%s' ORDER BY rank", eventID))
+	if err != nil {
+		return rankings, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var ranking Ranking
+		err = rows.Scan(&ranking.Team, &ranking.Rank, &ranking.Wins, &ranking.Losses, &ranking.Ties, &ranking.Played, &ranking.RankingScore)
+		if err != nil {
+			return rankings, err
+		}
+		rankings = append(rankings, ranking)
+	}
+	return rankings, nil
+}
+
+/*
+StorePriors replaces the prior ratings of the competitors at an event.
+*/
+func StorePriors(eventID string, priors []Prior) error {
+	for _, prior := range priors {
+		if GetCompetitorID(prior.Team) == "" {
+			CreateCompetitor(prior.Team, "")
+		}
+	}
+	tx, err := dbCampaigns.Begin()
+	if err != nil {
+		return err
+	}
+	_, err = tx.Exec(fmt.Sprintf("DELETE FROM priors WHERE eventid='%s'", eventID))
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	for _, prior := range priors {
+		_, err = tx.Exec(fmt.Sprintf("INSERT INTO priors VALUES ( '%s', '%s', '%s', '%v', '%v', '%v', '%v', '%v', '%v', '%v' )", eventID, GetCompetitorID(prior.Team), prior.Source, prior.Matches, prior.Overall, prior.Auto, prior.Shooting, prior.ColorWheel, prior.Climbing, prior.Fouls))
+		if err != nil {
+			tx.Rollback()
+			log.Errorf("Unable to store priors for event %s: %s", eventID, err.Error())
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+/*
+GetPriors gets the prior ratings of the competitors at an event, by team number.
+*/
+func GetPriors(eventID string) (map[int]Prior, error) {
+	priors := make(map[int]Prior)
+	rows, err := dbCampaigns.Query(fmt.Sprintf("SELECT competitors.number, source, matches, overall, auto, shooting, colorwheel, climbing, fouls FROM priors JOIN competitors ON priors.competitorid=competitors.competitorid WHERE eventid='%s'", eventID))
+	if err != nil {
+		return priors, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var prior Prior
+		err = rows.Scan(&prior.Team, &prior.Source, &prior.Matches, &prior.Overall, &prior.Auto, &prior.Shooting, &prior.ColorWheel, &prior.Climbing, &prior.Fouls)
+		if err != nil {
+			return priors, err
+		}
+		priors[prior.Team] = prior
+	}
+	return priors, nil
+}
+
+/*
+SetMatchTime changes when a match imported from The Blue Alliance is scheduled to start, in Unix time.
+*/
+func SetMatchTime(eventID, tbaKey string, start int64) error {
+	_, err := dbCampaigns.Exec(fmt.Sprintf("UPDATE matches SET time='%v' WHERE eventid='%s' AND tbakey='%s'", start, eventID, tbaKey))
+	return err
+}
+
+/*
+CreateEvent adds an event to the event table in the campaigns database
+Its starttime and endtime should be Unix time integers of its start and end dates
+*/
+func CreateEvent(campaignid, agentid, name, location string, starttime, endtime int) error {
+	eventid := uuid.New().String()
+	_, err := dbCampaigns.Exec(fmt.Sprintf("INSERT INTO events ( eventid, campaignid, name, location, starttime, endtime ) VALUES ( '%s', '%s', '%s', '%s', '%v', '%v' )", eventid, campaignid, escapeText(name), escapeText(location), starttime, endtime))
+	if err != nil {
+		log.Errorf("Unable to create event %s: %s", name, err.Error())
+	}
+	return err
+}
+
+/*
+CreateMatch adds a mach to the match table in the campaign database
+*/
+func CreateMatch(eventid, agentid string, num int, active bool) error {
+	matchid := uuid.New().String()
+	_, err := dbCampaigns.Exec(fmt.Sprintf("INSERT INTO matches ( matchid, eventid, matchnumber, active ) VALUES ( '%s', '%s', '%v', '%v' )", matchid, eventid, num, active))
+	if err == nil {
+		log.Infof("Created match #%v for event %s", num, eventid)
+	}
+	return err
+}
+
+/*
+TEAM MATCH DATA READ-WRITE FUNCTIONS
+*/
+
+func getCampaignOwner(campaignid string, db *sql.DB) string {
+	// TODO
+	return ""
+}
+
+func retconCompetitorID(teamnumber, teamid, DatabasePath string) {
+	// TODO
+}
+
+/*
+ResultsWrite writes
+*/
+func ResultsWrite(useriD, teamID string, data MatchData) {
+	// TODO
+	// Throw if overwriting existing
+}
+
+/*
+ResultsRead reads match results
+*/
+func ResultsRead() {
+
+}
+
+/*
+CreateCompetitor creates a competitor
+*/
+func CreateCompetitor(teamNumber int, name string) {
+	competitorID := uuid.New().String()
+	dbCampaigns.Exec(fmt.Sprintf("INSERT INTO competitors VALUES ( '%s', '%v', '%s' )", competitorID, teamNumber, name))
+}
+
+/*
+StoreCompetitor creates a competitor, or renames it if it already exists. An empty name leaves an existing competitor's name alone.
+*/
+func StoreCompetitor(teamNumber int, name string) error {
+	var err error
+	if GetCompetitorID(teamNumber) == "" {
+		_, err = dbCampaigns.Exec(fmt.Sprintf("INSERT INTO competitors VALUES ( '%s', '%v', '%s' )", uuid.New().String(), teamNumber, escapeText(name)))
+	} else if name != "" {
+		_, err = dbCampaigns.Exec(fmt.Sprintf("UPDATE competitors SET name='%s' WHERE number='%v'", escapeText(name), teamNumber))
+	}
+	if err != nil {
+		log.Errorf("Unable to store competitor %v: %s", teamNumber, err.Error())
+	}
+	return err
+}
+
+/*
+SetEventTeams sets which competitors are at an event, replacing the old list. Each competitor must already be stored. See StoreCompetitor.
+*/
+func SetEventTeams(eventID string, teams []int) error {
+	tx, err := dbCampaigns.Begin()
+	if err != nil {
+		return err
+	}
+	_, err = tx.Exec(fmt.Sprintf("DELETE FROM eventteams WHERE eventid='%s'", eventID))
+	if err != nil {
+		tx.Rollback()
+		log.Errorf("Unable to set teams at event %s: %s", eventID, err.Error())
+		return err
+	}
+	for _, team := range teams {
+		_, err = tx.Exec(fmt.Sprintf("INSERT OR IGNORE INTO eventteams VALUES ( '%s', '%s' )", eventID, GetCompetitorID(team)))
+		if err != nil {
+			tx.Rollback()
+			log.Errorf("Unable to set teams at event %s: %s", eventID, err.Error())
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+/*
+GetEventTeams gets the team numbers of the competitors at an event, in order.
+*/
+func GetEventTeams(eventID string) ([]int, error) {
+	var team int
+	teams := make([]int, 0)
+	rows, err := dbCampaigns.Query(fmt.Sprintf("SELECT competitors.number FROM eventteams JOIN competitors ON eventteams.competitorid=competitors.competitorid WHERE eventteams.eventid='%s' ORDER BY competitors.number", eventID))
+	if err != nil {
+		return teams, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		rows.Scan(&team)
+		teams = append(teams, team)
+	}
+	return teams, nil
 }
 
 /*
@@ -0,0 +1,69 @@
+package scouting
+
+import (
+	"EPIC-Scouting/lib/db"
+	"fmt"
+)
+
+//RobotCoverage is how well a robot in a match is being scouted
+type RobotCoverage struct {
+	Team      int
+	Alliance  string
+	Station   int
+	Scouts    []ScoutCoverage // The team's scouts assigned to the robot.
+	Submitted int             // Submissions received for the robot from any scout in the campaign, assigned or not.
+}
+
+//ScoutCoverage is how far a scout assigned to a robot has got
+type ScoutCoverage struct {
+	UserID    string
+	Opened    bool
+	Submitted bool
+}
+
+//Covered reports whether anyone is assigned to the robot or has already scouted it
+func (robot RobotCoverage) Covered() bool {
+	return len(robot.Scouts) > 0 || robot.Submitted > 0
+}
+
+//MatchCoverage gets how well each robot in one of a team's matches is being scouted, red alliance first
+func MatchCoverage(teamID string, match db.ScheduledMatch) ([]RobotCoverage, error) {
+	campaignID, err := db.GetTeamCampaign(teamID)
+	if err != nil {
+		return nil, err
+	}
+	assignments, err := db.GetScoutAssignments(teamID, match.MatchID)
+	if err != nil {
+		return nil, err
+	}
+	submissions, err := db.GetMatchSubmissions(campaignID, match.MatchID)
+	if err != nil {
+		return nil, err
+	}
+	robots := make([]RobotCoverage, 0, len(match.Red)+len(match.Blue))
+	for _, team := range matchParticipants(match) {
+		robot := RobotCoverage{Team: team, Scouts: make([]ScoutCoverage, 0)}
+		location := matchAssignment(match, team)
+		robot.Alliance, robot.Station = location.Alliance, location.Station
+		for _, assignment := range assignments {
+			if assignment.Team == team {
+				robot.Scouts = append(robot.Scouts, ScoutCoverage{UserID: assignment.UserID, Opened: assignment.Opened, Submitted: contains(submissions[assignment.UserID], team)})
+			}
+		}
+		for _, teams := range submissions {
+			if contains(teams, team) {
+				robot.Submitted++
+			}
+		}
+		robots = append(robots, robot)
+	}
+	return robots, nil
+}
+
+//Reassign moves one of a team's scouts to a different robot in a match
+func Reassign(teamID, userid string, match db.ScheduledMatch, team int) error {
+	if !contains(matchParticipants(match), team) {
+		return fmt.Errorf("%v is not playing in match %v", team, match.MatchNum)
+	}
+	return db.WriteAssignment(teamID, match.MatchID, userid, team)
+}
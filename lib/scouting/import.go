@@ -0,0 +1,124 @@
+package scouting
+
+import (
+	"EPIC-Scouting/lib/calc"
+	"EPIC-Scouting/lib/db"
+	"EPIC-Scouting/lib/tba"
+	"fmt"
+	"strings"
+)
+
+//ImportEvent copies an event from The Blue Alliance into a campaign, along with every team at it and every match in its schedule, qualifications and playoffs alike, and the official scores of matches already played. Returns the eventid.
+//Importing an event again updates it in place, so it can be re-run as TBA fills in the playoff schedule. Returns ErrStale along with the eventid if any of it came from the cache because TBA couldn't be reached
+func ImportEvent(client *tba.Client, campaignID, eventKey string) (string, error) {
+	event, err := client.GetEvent(eventKey)
+	if err != nil && err != tba.ErrStale {
+		return "", err
+	}
+	stale := err
+	teams, err := client.GetEventTeams(eventKey)
+	if err == tba.ErrStale {
+		stale = err
+	} else if err != nil {
+		return "", err
+	}
+	matches, err := client.GetEventMatches(eventKey)
+	if err == tba.ErrStale {
+		stale = err
+	} else if err != nil {
+		return "", err
+	}
+	eventID, err := storeEvent(campaignID, event.Key, event, teams, matches)
+	if err != nil {
+		return eventID, err
+	}
+	return eventID, stale
+}
+
+//storeEvent stores an event from TBA, its teams and its matches in a campaign under a key. An empty key stores a new copy which is never synced with TBA. Returns the eventid
+func storeEvent(campaignID, tbaKey string, event tba.Event, teams []tba.Team, matches []tba.Match) (string, error) {
+	start, end, err := event.Times()
+	if err != nil {
+		return "", err
+	}
+	location := make([]string, 0, 3)
+	for _, part := range []string{event.City, event.StateProv, event.Country} {
+		if part != "" {
+			location = append(location, part)
+		}
+	}
+	eventID, err := db.StoreEvent(campaignID, tbaKey, event.Name, strings.Join(location, ", "), start.Unix(), end.Unix())
+	if err != nil {
+		return "", err
+	}
+	numbers := make([]int, 0, len(teams))
+	for _, team := range teams {
+		err = db.StoreCompetitor(team.TeamNumber, team.Nickname)
+		if err != nil {
+			return eventID, err
+		}
+		numbers = append(numbers, team.TeamNumber)
+	}
+	err = db.SetEventTeams(eventID, numbers)
+	if err != nil {
+		return eventID, err
+	}
+	for _, match := range matches {
+		err = storeMatch(eventID, match)
+		if err != nil {
+			return eventID, err
+		}
+	}
+	//the teams and schedule may have changed along with the matches
+	calc.InvalidateEvent(eventID)
+	return eventID, nil
+}
+
+//storeMatch stores a match from TBA in an imported event, along with its official score breakdown once it has been played
+func storeMatch(eventID string, match tba.Match) error {
+	imported, err := importedMatch(match)
+	if err != nil {
+		return err
+	}
+	matchID, err := db.StoreImportedMatch(eventID, imported)
+	if err != nil || match.ScoreBreakdown == nil {
+		return err
+	}
+	for alliance, breakdown := range map[string]tba.AllianceBreakdown{"red": match.ScoreBreakdown.Red, "blue": match.ScoreBreakdown.Blue} {
+		err = db.StoreOfficialScore(db.OfficialScore{MatchID: matchID, Alliance: alliance, AutoPoints: breakdown.AutoPoints, TeleopCellPoints: breakdown.TeleopCellPoints, EndgamePoints: breakdown.EndgamePoints, FoulPoints: breakdown.FoulPoints, TotalPoints: breakdown.TotalPoints})
+		if err != nil {
+			return err
+		}
+	}
+	//match summaries share out scoring gaps using official scores
+	calc.InvalidateMatch(eventID, matchID)
+	return nil
+}
+
+//importedMatch converts a match from TBA into the form it is stored in
+func importedMatch(match tba.Match) (db.ImportedMatch, error) {
+	var err error
+	imported := db.ImportedMatch{Key: match.Key, CompLevel: match.CompLevel, SetNumber: match.SetNumber, MatchNum: match.MatchNumber, Time: match.Time}
+	imported.Red, err = teamNumbers(match.Alliances.Red.TeamKeys)
+	if err != nil {
+		return imported, fmt.Errorf("match %s: %s", match.Key, err.Error())
+	}
+	imported.Blue, err = teamNumbers(match.Alliances.Blue.TeamKeys)
+	if err != nil {
+		return imported, fmt.Errorf("match %s: %s", match.Key, err.Error())
+	}
+	return imported, nil
+}
+
+//teamNumbers converts TBA's team keys into team numbers, keeping their order
+func teamNumbers(keys []string) ([]int, error) {
+	numbers := make([]int, 0, len(keys))
+	for _, key := range keys {
+		number, err := tba.TeamNumber(key)
+		if err != nil {
+			return nil, err
+		}
+		numbers = append(numbers, number)
+	}
+	return numbers, nil
+}
@@ -0,0 +1,207 @@
+package scouting
+
+import (
+	"EPIC-Scouting/lib/calc"
+	"EPIC-Scouting/lib/db"
+	"EPIC-Scouting/lib/tba"
+	"errors"
+	"math"
+	"time"
+)
+
+//Where prior ratings can come from. A team rated from both is given PriorsFromBoth as its source
+const (
+	PriorsFromTBA       = "tba"
+	PriorsFromCampaigns = "campaigns"
+	PriorsFromBoth      = "both"
+)
+
+//ErrNoPriorSource is returned when priors are built without anywhere to build them from
+var ErrNoPriorSource = errors.New("no source for prior ratings was chosen")
+
+//BuildPriors rates every team at the event a team is scheduled on from before the event, and stores the ratings so calc can blend them with scouting until the team's matches there are scouted. Returns how many teams were rated.
+//Ratings come from the official score breakdowns of the team's earlier events that season on TBA, from a team's own scouting at earlier events that season in its other campaigns, or from both. Returns ErrStale along with the count if any of TBA's data came from the cache
+func BuildPriors(client *tba.Client, teamID string, fromTBA, fromCampaigns bool) (int, error) {
+	if !fromTBA && !fromCampaigns {
+		return 0, ErrNoPriorSource
+	}
+	campaignID, eventID, err := db.GetTeamSchedule(teamID)
+	if err != nil {
+		return 0, err
+	}
+	start, _, err := db.GetEventTimes(eventID)
+	if err != nil {
+		return 0, err
+	}
+	eventKey, _ := db.GetEventTBAKey(eventID)
+	season := time.Unix(start, 0).Year()
+	var stale error
+	teams, err := priorTeams(client, eventID, eventKey, fromTBA)
+	if err == tba.ErrStale {
+		stale = err
+	} else if err != nil {
+		return 0, err
+	}
+	earlier := make(map[int][]db.MatchData)
+	if fromCampaigns {
+		since := time.Date(season, time.January, 1, 0, 0, 0, 0, time.Local).Unix()
+		earlier, err = pastResults(teamID, campaignID, since, start)
+		if err != nil {
+			return 0, err
+		}
+	}
+	priors := make([]db.Prior, 0, len(teams))
+	for _, team := range teams {
+		matches := earlier[team]
+		source := PriorsFromCampaigns
+		if fromTBA {
+			official, err := officialPriorMatches(client, team, eventKey, season, start)
+			if err == tba.ErrStale {
+				stale = err
+			} else if err != nil {
+				return 0, err
+			}
+			if len(official) > 0 {
+				source = PriorsFromTBA
+				if len(matches) > 0 {
+					source = PriorsFromBoth
+				}
+			}
+			matches = append(matches, official...)
+		}
+		if len(matches) == 0 {
+			continue
+		}
+		priors = append(priors, db.Prior{Team: team, Source: source, Matches: len(matches), Overall: calc.Overall(matches), Auto: calc.Auto(matches), Shooting: calc.Shooting(matches), ColorWheel: calc.ColorWheel(matches), Climbing: calc.Climbing(matches), Fouls: calc.Foul(matches)})
+	}
+	err = db.StorePriors(eventID, priors)
+	if err != nil {
+		return 0, err
+	}
+	calc.InvalidateEvent(eventID)
+	return len(priors), stale
+}
+
+//priorTeams lists the teams at an event: every team in its schedule, along with TBA's team list for events imported from there, since priors are most useful before the schedule is out
+func priorTeams(client *tba.Client, eventID, eventKey string, fromTBA bool) ([]int, error) {
+	seen := make(map[int]bool)
+	teams := make([]int, 0)
+	add := func(team int) {
+		if !seen[team] {
+			seen[team] = true
+			teams = append(teams, team)
+		}
+	}
+	schedule, err := db.GetEventSchedule(eventID)
+	if err != nil {
+		return nil, err
+	}
+	for _, match := range schedule {
+		for _, team := range append(append([]int{}, match.Red...), match.Blue...) {
+			add(team)
+		}
+	}
+	if !fromTBA || eventKey == "" {
+		return teams, nil
+	}
+	listed, stale := client.GetEventTeams(eventKey)
+	if stale != nil && stale != tba.ErrStale {
+		return nil, stale
+	}
+	for _, team := range listed {
+		add(team.TeamNumber)
+	}
+	return teams, stale
+}
+
+//pastResults gets the resolved results of every team the scouting team scouted at events in its other campaigns which ran between since and before, by team number
+func pastResults(teamID, campaignID string, since, before int64) (map[int][]db.MatchData, error) {
+	results, err := db.GetPastResults(teamID, campaignID, since, before)
+	if err != nil {
+		return nil, err
+	}
+	//match numbers repeat from event to event, so each match is resolved on its own
+	byMatch := make(map[int]map[string][]db.MatchData)
+	for _, result := range calc.ApplyRulings(*results) {
+		if byMatch[result.Team] == nil {
+			byMatch[result.Team] = make(map[string][]db.MatchData)
+		}
+		byMatch[result.Team][result.MatchID] = append(byMatch[result.Team][result.MatchID], result)
+	}
+	resolved := make(map[int][]db.MatchData)
+	for team, matches := range byMatch {
+		for _, scouted := range matches {
+			resolved[team] = append(resolved[team], calc.ResolveMatchList(scouted)...)
+		}
+	}
+	return resolved, nil
+}
+
+//officialPriorMatches converts a team's played matches at its earlier events in a season on TBA into results, as if each had been scouted. Events which hadn't finished by before, in Unix time, and the event being rated are left out
+func officialPriorMatches(client *tba.Client, team int, eventKey string, season int, before int64) ([]db.MatchData, error) {
+	events, err := client.GetTeamEvents(tba.TeamKey(team))
+	if err != nil && err != tba.ErrStale {
+		return nil, err
+	}
+	stale := err
+	earlier := make(map[string]bool)
+	for _, event := range events {
+		if event.Year != season || event.Key == eventKey {
+			continue
+		}
+		_, end, err := event.Times()
+		if err == nil && end.Unix() <= before {
+			earlier[event.Key] = true
+		}
+	}
+	if len(earlier) == 0 {
+		return nil, stale
+	}
+	matches, err := client.GetTeamMatches(tba.TeamKey(team), season)
+	if err == tba.ErrStale {
+		stale = err
+	} else if err != nil {
+		return nil, err
+	}
+	results := make([]db.MatchData, 0, len(matches))
+	for _, match := range matches {
+		if !earlier[match.EventKey] || match.ScoreBreakdown == nil {
+			continue
+		}
+		if result, ok := officialResult(match, team); ok {
+			results = append(results, result)
+		}
+	}
+	return results, stale
+}
+
+//officialResult converts a team's part in a played match into a result, as if it had been scouted. Whether it left the initiation line and how it ended the match are its own. Power cells are only counted by alliance, so each robot is credited with an even share.
+//Misses aren't in the breakdown, so every shot is counted as scored. Fouls are left out, since the breakdown only has those the other alliance committed, and the color wheel is left out since its times aren't recorded
+func officialResult(match tba.Match, team int) (db.MatchData, bool) {
+	alliance, station := replayStation(match, team)
+	if station == 0 {
+		return db.MatchData{}, false
+	}
+	teamKeys, breakdown := match.Alliances.Red.TeamKeys, match.ScoreBreakdown.Red
+	if alliance == "blue" {
+		teamKeys, breakdown = match.Alliances.Blue.TeamKeys, match.ScoreBreakdown.Blue
+	}
+	share := func(cells int) int {
+		return int(math.Round(float64(cells) / float64(len(teamKeys))))
+	}
+	result := db.MatchData{MatchID: match.Key, MatchNum: match.MatchNumber, Team: team}
+	result.AutoLineCross = []string{breakdown.InitLineRobot1, breakdown.InitLineRobot2, breakdown.InitLineRobot3}[station-1] == "Exited"
+	result.AutoLowBalls, result.AutoHighBalls, result.AutoBackBalls = share(breakdown.AutoCellsBottom), share(breakdown.AutoCellsOuter), share(breakdown.AutoCellsInner)
+	result.AutoShots = result.AutoLowBalls + result.AutoHighBalls + result.AutoBackBalls
+	result.LowFuel, result.HighFuel, result.BackFuel = share(breakdown.TeleopCellsBottom), share(breakdown.TeleopCellsOuter), share(breakdown.TeleopCellsInner)
+	result.ShotQuantity = result.LowFuel + result.HighFuel + result.BackFuel
+	switch []string{breakdown.EndgameRobot1, breakdown.EndgameRobot2, breakdown.EndgameRobot3}[station-1] {
+	case "Hang":
+		result.Climbed = "climbed"
+		result.ClimbAttempted = true
+		result.Balanced = breakdown.EndgameRungIsLevel == "IsLevel"
+	case "Park":
+		result.Climbed = "platform"
+	}
+	return result, true
+}
@@ -0,0 +1,298 @@
+package scouting
+
+import (
+	"EPIC-Scouting/lib/db"
+	"EPIC-Scouting/lib/tba"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+//ReplayDir is where recorded events for practice scouting are kept. Each is a JSON file named for its event
+var ReplayDir = "./static/replays/"
+
+//ErrReplayRunning is returned when a team starts a practice session while one is already running
+var ErrReplayRunning = errors.New("a replay session is already running")
+
+//ErrNoReplay is returned when a team ends a practice session without one running
+var ErrNoReplay = errors.New("no replay session is running")
+
+//Replay is a recorded past event: TBA's responses for the event, its teams and its matches, with official score breakdowns
+type Replay struct {
+	Event   tba.Event   `json:"event"`
+	Teams   []tba.Team  `json:"teams"`
+	Matches []tba.Match `json:"matches"`
+}
+
+//TraineeGrade is how closely a trainee's submissions in a practice session matched the official score breakdowns
+type TraineeGrade struct {
+	UserID         string
+	Robots         int            // Robots the trainee scouted.
+	Checks         int            // Things the trainee recorded which the breakdowns say for certain, such as whether a robot climbed.
+	Correct        int            // Checks the trainee got right.
+	Mistakes       map[string]int // How many times each check was wrong.
+	CellAlliances  int            // Alliances the trainee scouted in which every robot was scouted, so their power cells can be compared.
+	CellDifference float64        // How many power cells those alliances' scouted counts were off by, on average.
+}
+
+//Accuracy gets the share of checks a trainee got right, from 0 to 1
+func (grade TraineeGrade) Accuracy() float64 {
+	if grade.Checks == 0 {
+		return 0
+	}
+	return float64(grade.Correct) / float64(grade.Checks)
+}
+
+//ReplayList gets the names of the recorded events that can be replayed
+func ReplayList() ([]string, error) {
+	files, err := filepath.Glob(filepath.Join(ReplayDir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(files))
+	for _, file := range files {
+		names = append(names, strings.TrimSuffix(filepath.Base(file), ".json"))
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+//LoadReplay reads a recorded event by name
+func LoadReplay(name string) (Replay, error) {
+	var replay Replay
+	if name == "" || name != filepath.Base(name) {
+		return replay, fmt.Errorf("%q is not a recorded event", name)
+	}
+	data, err := ioutil.ReadFile(filepath.Join(ReplayDir, name+".json"))
+	if err != nil {
+		return replay, err
+	}
+	err = json.Unmarshal(data, &replay)
+	if err != nil {
+		return replay, fmt.Errorf("recorded event %s could not be read: %s", name, err.Error())
+	}
+	return replay, nil
+}
+
+//RecordReplay saves a past event from TBA so it can be replayed offline. It is named for its event key
+func RecordReplay(client *tba.Client, eventKey string) error {
+	var replay Replay
+	var err error
+	replay.Event, err = client.GetEvent(eventKey)
+	if err != nil {
+		return err
+	}
+	replay.Teams, err = client.GetEventTeams(eventKey)
+	if err != nil {
+		return err
+	}
+	replay.Matches, err = client.GetEventMatches(eventKey)
+	if err != nil {
+		return err
+	}
+	for _, match := range replay.Matches {
+		if match.CompLevel == "qm" && match.ScoreBreakdown == nil {
+			return fmt.Errorf("match %s has not been played, so %s can't be replayed yet", match.Key, eventKey)
+		}
+	}
+	data, err := json.Marshal(replay)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(filepath.Join(ReplayDir, filepath.Base(replay.Event.Key)+".json"), data, 0644)
+}
+
+//StartReplay starts a practice scouting session for a team on a recorded event. The event is loaded into a new sandbox campaign without its official scores, and the team is moved onto it in match scouting, so its match control sets the pace.
+//Only a team's last session can be reviewed, so the sandbox of the one before is deleted
+func StartReplay(teamID, name string) error {
+	last, lastErr := db.GetReplaySession(teamID)
+	if lastErr == nil && last.Ended == "" {
+		return ErrReplayRunning
+	}
+	replay, err := LoadReplay(name)
+	if err != nil {
+		return err
+	}
+	//a team which was never given a schedule goes back to having none
+	previous, _ := db.GetSchedule(teamID)
+	campaignID, err := db.CreateSandboxCampaign(teamID, "Replay: "+replay.Event.Name)
+	if err != nil {
+		return err
+	}
+	//scores are left out until the session ends so the trainees can't look them up
+	matches := make([]tba.Match, len(replay.Matches))
+	for ind, match := range replay.Matches {
+		match.ScoreBreakdown = nil
+		matches[ind] = match
+	}
+	eventID, err := storeEvent(campaignID, "", replay.Event, replay.Teams, matches)
+	if err == nil {
+		err = db.StartReplaySession(teamID, db.ReplaySession{Fixture: name, CampaignID: campaignID, EventID: eventID, Previous: previous})
+	}
+	if err != nil {
+		db.DeleteSandboxCampaign(campaignID)
+		return err
+	}
+	if lastErr == nil {
+		db.DeleteSandboxCampaign(last.CampaignID)
+	}
+	return db.SetSchedule(teamID, db.Schedule{CampaignID: campaignID, EventID: eventID, Mode: "match"})
+}
+
+//EndReplay ends a team's practice scouting session, grades each trainee and puts the team back on what it was scouting before. The official scores are added to the sandbox campaign so the session can be reviewed like a real event
+func EndReplay(teamID string) ([]TraineeGrade, error) {
+	session, err := db.GetReplaySession(teamID)
+	if err != nil || session.Ended != "" {
+		return nil, ErrNoReplay
+	}
+	replay, err := LoadReplay(session.Fixture)
+	if err != nil {
+		return nil, err
+	}
+	for _, match := range replay.Matches {
+		err = storeMatch(session.EventID, match)
+		if err != nil {
+			return nil, err
+		}
+	}
+	err = db.SetSchedule(teamID, session.Previous)
+	if err != nil {
+		return nil, err
+	}
+	err = db.EndReplaySession(teamID)
+	if err != nil {
+		return nil, err
+	}
+	return GradeReplay(session)
+}
+
+//GradeReplay grades each trainee's submissions in a practice session against the recorded official score breakdowns, most accurate first.
+//Whether each robot left the initiation line and how it ended the match are checked robot by robot. Power cells are only counted by alliance, so they are compared for alliances in which every robot was scouted
+func GradeReplay(session db.ReplaySession) ([]TraineeGrade, error) {
+	replay, err := LoadReplay(session.Fixture)
+	if err != nil {
+		return nil, err
+	}
+	results, err := db.GetEventResults(session.EventID)
+	if err != nil {
+		return nil, err
+	}
+	qualifications := make(map[int]tba.Match)
+	for _, match := range replay.Matches {
+		if match.CompLevel == "qm" && match.ScoreBreakdown != nil {
+			qualifications[match.MatchNumber] = match
+		}
+	}
+	grades := make(map[string]*TraineeGrade)
+	type allianceKey struct {
+		MatchNum int
+		Alliance string
+	}
+	allianceResults := make(map[allianceKey][]db.MatchData)
+	for _, result := range *results {
+		match, ok := qualifications[result.MatchNum]
+		if !ok {
+			continue
+		}
+		alliance, station := replayStation(match, result.Team)
+		if station == 0 {
+			continue
+		}
+		grade, ok := grades[result.UserID]
+		if !ok {
+			grade = &TraineeGrade{UserID: result.UserID, Mistakes: make(map[string]int)}
+			grades[result.UserID] = grade
+		}
+		grade.Robots++
+		breakdown := match.ScoreBreakdown.Red
+		if alliance == "blue" {
+			breakdown = match.ScoreBreakdown.Blue
+		}
+		initLine := []string{breakdown.InitLineRobot1, breakdown.InitLineRobot2, breakdown.InitLineRobot3}[station-1]
+		endgame := []string{breakdown.EndgameRobot1, breakdown.EndgameRobot2, breakdown.EndgameRobot3}[station-1]
+		grade.check("initiation line", result.AutoLineCross == (initLine == "Exited"))
+		grade.check("endgame", scoutedEndgame(result.Climbed) == endgame)
+		key := allianceKey{MatchNum: result.MatchNum, Alliance: alliance}
+		allianceResults[key] = append(allianceResults[key], result)
+	}
+	for key, results := range allianceResults {
+		match := qualifications[key.MatchNum]
+		teamKeys, breakdown := match.Alliances.Red.TeamKeys, match.ScoreBreakdown.Red
+		if key.Alliance == "blue" {
+			teamKeys, breakdown = match.Alliances.Blue.TeamKeys, match.ScoreBreakdown.Blue
+		}
+		//each robot's cells are averaged over everyone who scouted it
+		cells := make(map[int][]int)
+		for _, result := range results {
+			cells[result.Team] = append(cells[result.Team], result.AutoLowBalls+result.AutoHighBalls+result.AutoBackBalls+result.LowFuel+result.HighFuel+result.BackFuel)
+		}
+		if len(cells) < len(teamKeys) {
+			continue
+		}
+		scouted := 0.0
+		for _, counts := range cells {
+			scouted += float64(sum(counts)) / float64(len(counts))
+		}
+		official := breakdown.AutoCellsBottom + breakdown.AutoCellsOuter + breakdown.AutoCellsInner + breakdown.TeleopCellsBottom + breakdown.TeleopCellsOuter + breakdown.TeleopCellsInner
+		difference := math.Abs(scouted - float64(official))
+		graded := make(map[string]bool)
+		for _, result := range results {
+			if graded[result.UserID] {
+				continue
+			}
+			graded[result.UserID] = true
+			grade := grades[result.UserID]
+			grade.CellDifference = (grade.CellDifference*float64(grade.CellAlliances) + difference) / float64(grade.CellAlliances+1)
+			grade.CellAlliances++
+		}
+	}
+	sorted := make([]TraineeGrade, 0, len(grades))
+	for _, grade := range grades {
+		sorted = append(sorted, *grade)
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].Accuracy() != sorted[j].Accuracy() {
+			return sorted[i].Accuracy() > sorted[j].Accuracy()
+		}
+		return sorted[i].UserID < sorted[j].UserID
+	})
+	return sorted, nil
+}
+
+//check records whether a trainee got one thing right
+func (grade *TraineeGrade) check(name string, correct bool) {
+	grade.Checks++
+	if correct {
+		grade.Correct++
+	} else {
+		grade.Mistakes[name]++
+	}
+}
+
+//replayStation finds which alliance and driver station a team played from in a recorded match. The station is 0 if the team wasn't in it
+func replayStation(match tba.Match, team int) (string, int) {
+	for alliance, teamKeys := range map[string][]string{"red": match.Alliances.Red.TeamKeys, "blue": match.Alliances.Blue.TeamKeys} {
+		for ind, key := range teamKeys {
+			if key == tba.TeamKey(team) {
+				return alliance, ind + 1
+			}
+		}
+	}
+	return "", 0
+}
+
+//scoutedEndgame converts how a scout recorded a robot's endgame into how the official breakdown describes it
+func scoutedEndgame(climbed string) string {
+	switch climbed {
+	case "climbed":
+		return "Hang"
+	case "platform":
+		return "Park"
+	}
+	return "None"
+}
@@ -1,54 +1,484 @@
 package scouting
 
-import "math"
+import (
+	"EPIC-Scouting/lib/calc"
+	"EPIC-Scouting/lib/db"
+	"errors"
+	"fmt"
+	"sort"
+)
+
+//DefaultPriority is the priority of competitors a team hasn't given a priority
+const DefaultPriority = 1
 
 //Scheduler gets data for teams being scouted in the next match
 
 //GetTeamInfo gets number of scouters an other information from a specific competitior team
 
-//GetTeamMatch gets which match the team is on, updated by the team admin
+//GetTeamMatch gets which match the team is on, updated by the team admin. Until an admin has set one, the team is on its next unscouted match
+func GetTeamMatch(teamID string) (db.ScheduledMatch, error) {
+	_, eventID, err := db.GetTeamSchedule(teamID)
+	if err != nil {
+		return db.ScheduledMatch{}, err
+	}
+	matchID, err := db.GetCurrentMatch(teamID, eventID)
+	if err == nil {
+		schedule, err := db.GetEventSchedule(eventID)
+		if err != nil {
+			return db.ScheduledMatch{}, err
+		}
+		if ind := scheduleIndex(schedule, matchID); ind >= 0 {
+			return schedule[ind], nil
+		}
+	}
+	return NextMatch(teamID)
+}
 
-//GetScouterMatch gets which match a specific scouter is on
+//Assignment is a robot a scout has been given to watch in a match. Team is 0 if the robot hasn't been picked yet
+type Assignment struct {
+	MatchID  string
+	MatchNum int
+	Team     int
+	Alliance string // "red" or "blue".
+	Station  int    // 1 to 3.
+}
 
-//AssignNewUser assigns a user who is ready to scout to the next match
-func AssignNewUser(userid string) string {
-	priorities := getPriorityMap("match")
-	totalPriority := 0
-	scoutersWanted := make(map[string]int, 0)
-	toScout := ""
-	for _, priority := range priorities {
-		totalPriority += priority
+//GetScouterMatch gets which match and robot a specific scouter is on, assigning them a robot in the team's next match if they don't have one
+func GetScouterMatch(userid, teamID string) (Assignment, error) {
+	match, err := GetTeamMatch(teamID)
+	if err != nil {
+		return Assignment{}, err
 	}
-	for team, priority := range priorities {
-		scoutersWanted[team] = int(math.Round(float64(priority) / float64(totalPriority)))
+	team, err := AssignNewUser(userid, teamID)
+	if err != nil {
+		return Assignment{}, err
 	}
-	topPriority := 0
-	currentScouters := 0
-	for team, scouters := range scoutersWanted {
-		currentScouters = 0 //TODO get how many scouters each team has
-		if (scouters - currentScouters) > topPriority {
-			topPriority = scouters
-			toScout = team
+	return matchAssignment(match, team), nil
+}
+
+//GetScouterNextMatch gets where a scouter is expected after the team's current match: the following match they are on shift for, or simply the following match if the team has no shift schedule.
+//The robot is only a preview, worked out from who is on shift, since robots are given out as scouts ask for them
+func GetScouterNextMatch(userid, teamID string) (Assignment, error) {
+	_, eventID, err := db.GetTeamSchedule(teamID)
+	if err != nil {
+		return Assignment{}, err
+	}
+	current, err := GetTeamMatch(teamID)
+	if err != nil {
+		return Assignment{}, err
+	}
+	schedule, err := db.GetEventSchedule(eventID)
+	if err != nil {
+		return Assignment{}, err
+	}
+	shifts, err := db.GetShifts(teamID, eventID)
+	if err != nil {
+		return Assignment{}, err
+	}
+	for _, match := range schedule {
+		if match.MatchNum <= current.MatchNum {
+			continue
+		}
+		if len(shifts) == 0 {
+			return matchAssignment(match, 0), nil
+		}
+		scouts := shifts[match.MatchID]
+		if !containsString(scouts, userid) {
+			continue
 		}
+		robots := matchParticipants(match)
+		return matchAssignment(match, Assign(robots, getPriorityMap(teamID, eventID, robots), scouts)[userid]), nil
 	}
-	//Add userid to scout team in toScout
-	return toScout
+	return Assignment{}, errors.New("no later matches are scheduled for this scout")
+}
+
+//matchAssignment describes where a robot is in a match
+func matchAssignment(match db.ScheduledMatch, team int) Assignment {
+	assignment := Assignment{MatchID: match.MatchID, MatchNum: match.MatchNum, Team: team}
+	for ind, robot := range match.Red {
+		if robot == team {
+			assignment.Alliance, assignment.Station = "red", ind+1
+		}
+	}
+	for ind, robot := range match.Blue {
+		if robot == team {
+			assignment.Alliance, assignment.Station = "blue", ind+1
+		}
+	}
+	return assignment
+}
+
+//AssignNewUser assigns a user who is ready to scout to a robot in a team's next match and returns the robot's team number.
+//Users already assigned in the match keep their assignment. Every robot is covered before any robot gets a second scout
+func AssignNewUser(userid, teamID string) (int, error) {
+	_, eventID, err := db.GetTeamSchedule(teamID)
+	if err != nil {
+		return 0, err
+	}
+	match, err := GetTeamMatch(teamID)
+	if err != nil {
+		return 0, err
+	}
+	robots := matchParticipants(match)
+	if len(robots) == 0 {
+		return 0, fmt.Errorf("no robots are scheduled in match %v", match.MatchNum)
+	}
+	assignments, err := db.GetMatchAssignments(teamID, match.MatchID)
+	if err != nil {
+		return 0, err
+	}
+	if team, ok := assignments[userid]; ok && contains(robots, team) {
+		return team, nil
+	}
+	counts := make(map[int]int)
+	for _, team := range assignments {
+		counts[team]++
+	}
+	toScout := pickScoutedTeam(robots, getPriorityMap(teamID, eventID, robots), counts)
+	err = db.WriteAssignment(teamID, match.MatchID, userid, toScout)
+	return toScout, err
 }
 
-//TODO getPrioriyMap gets a map of the teams in the next match along with their correlating priorities
-func getPriorityMap(matchid string) map[string]int {
-	pmap := make(map[string]int, 6)
+//AssignMatch assigns a group of available scouts to the robots in one of a team's matches, replacing any earlier assignments for the match
+func AssignMatch(teamID string, match db.ScheduledMatch, userids []string) (map[string]int, error) {
+	_, eventID, err := db.GetTeamSchedule(teamID)
+	if err != nil {
+		return nil, err
+	}
+	robots := matchParticipants(match)
+	assignments := Assign(robots, getPriorityMap(teamID, eventID, robots), userids)
+	err = db.ClearMatchAssignments(teamID, match.MatchID)
+	if err != nil {
+		return assignments, err
+	}
+	for _, userid := range userids {
+		team, ok := assignments[userid]
+		if !ok {
+			continue
+		}
+		err = db.WriteAssignment(teamID, match.MatchID, userid, team)
+		if err != nil {
+			return assignments, err
+		}
+	}
+	return assignments, nil
+}
+
+//Assign works out which robot each scout should watch, in the order the scouts are listed.
+//Every robot is covered before any robot gets a second scout, and extra scouts go to the robots with the most priority per scout already watching them
+func Assign(robots []int, priorities map[int]int, userids []string) map[string]int {
+	assignments := make(map[string]int)
+	if len(robots) == 0 {
+		return assignments
+	}
+	counts := make(map[int]int)
+	for _, userid := range userids {
+		team := pickScoutedTeam(robots, priorities, counts)
+		assignments[userid] = team
+		counts[team]++
+	}
+	return assignments
+}
+
+//getPriorityMap gets the priority of each robot in a match for a team
+func getPriorityMap(teamID, eventID string, robots []int) map[int]int {
+	pmap := make(map[int]int, len(robots))
+	priorities, _ := db.GetCompetitorPriorities(teamID, eventID)
+	for _, team := range robots {
+		priority, ok := priorities[team]
+		if !ok {
+			priority = DefaultPriority
+		}
+		pmap[team] = priority
+	}
 	return pmap
 }
 
-//UpdateMatch updates the match to be scouted
+//UpdateMatch updates the match to be scouted, jumping straight to a match number
+func UpdateMatch(teamID string, matchNum int) (db.ScheduledMatch, error) {
+	_, eventID, err := db.GetTeamSchedule(teamID)
+	if err != nil {
+		return db.ScheduledMatch{}, err
+	}
+	schedule, err := db.GetEventSchedule(eventID)
+	if err != nil {
+		return db.ScheduledMatch{}, err
+	}
+	for _, match := range schedule {
+		if match.MatchNum == matchNum {
+			return match, moveToMatch(teamID, eventID, match.MatchID)
+		}
+	}
+	return db.ScheduledMatch{}, fmt.Errorf("match %v is not on the schedule", matchNum)
+}
+
+//StepMatch moves the match to be scouted forward or back through the schedule by a number of matches. Delayed matches are skipped going forward, since they will be played later
+func StepMatch(teamID string, step int) (db.ScheduledMatch, error) {
+	_, eventID, err := db.GetTeamSchedule(teamID)
+	if err != nil {
+		return db.ScheduledMatch{}, err
+	}
+	current, err := GetTeamMatch(teamID)
+	if err != nil {
+		return db.ScheduledMatch{}, err
+	}
+	schedule, err := db.GetEventSchedule(eventID)
+	if err != nil {
+		return db.ScheduledMatch{}, err
+	}
+	if len(schedule) == 0 {
+		return db.ScheduledMatch{}, errors.New("no matches are scheduled")
+	}
+	//if the current match has gone from the schedule, stepping forward starts from the first match
+	ind := scheduleIndex(schedule, current.MatchID)
+	for step > 0 && ind < len(schedule)-1 {
+		ind++
+		if schedule[ind].Status != "delayed" {
+			step--
+		}
+	}
+	for step < 0 && ind > 0 {
+		ind--
+		step++
+	}
+	if ind < 0 {
+		ind = 0
+	}
+	match := schedule[ind]
+	return match, moveToMatch(teamID, eventID, match.MatchID)
+}
+
+//moveToMatch makes a match the one a team is scouting and its event's active match, and recalculates the event's cached results, which depend on where the event is up to
+func moveToMatch(teamID, eventID, matchID string) error {
+	err := db.SetCurrentMatch(teamID, eventID, matchID)
+	if err != nil {
+		return err
+	}
+	calc.InvalidateEvent(eventID)
+	return nil
+}
+
+//NextMatch gets the first match on a team's schedule at its active event which nobody in its campaign has scouted yet
+func NextMatch(teamID string) (db.ScheduledMatch, error) {
+	campaignID, eventID, err := db.GetTeamSchedule(teamID)
+	if err != nil {
+		return db.ScheduledMatch{}, err
+	}
+	schedule, err := db.GetEventSchedule(eventID)
+	if err != nil {
+		return db.ScheduledMatch{}, err
+	}
+	scouted, err := db.GetScoutedMatches(campaignID, eventID)
+	if err != nil {
+		return db.ScheduledMatch{}, err
+	}
+	for _, match := range schedule {
+		if !scouted[match.MatchID] {
+			return match, nil
+		}
+	}
+	return db.ScheduledMatch{}, errors.New("no scheduled matches are left to scout")
+}
 
-//NextMatch gets data on which match is next to be scouted
+//GenerateShifts generates a team's scout shift schedule for its whole active event from its roster, the qualification schedule and its shift rules, replacing any earlier shift schedule
+func GenerateShifts(teamID string) (map[string][]string, error) {
+	_, eventID, err := db.GetTeamSchedule(teamID)
+	if err != nil {
+		return nil, err
+	}
+	schedule, err := db.GetEventSchedule(eventID)
+	if err != nil {
+		return nil, err
+	}
+	roster, err := db.GetTeamMembers(teamID)
+	if err != nil {
+		return nil, err
+	}
+	rules, err := db.GetShiftRules(teamID)
+	if err != nil {
+		return nil, err
+	}
+	ownTeam, _ := db.GetTeamNumber(teamID)
+	shifts := PlanShifts(schedule, roster, rules, ownTeam)
+	err = db.WriteShifts(teamID, eventID, shifts)
+	return shifts, err
+}
 
-//openSchedule deserializes the schedule from the database
+//PlanShifts works out which scouts are on shift for each match, keyed by matchid.
+//Scouts who have been on shift the least go first, so work is spread evenly. Nobody is on shift for more than rules.MaxConsecutive matches in a row without sitting out rules.BreakLength matches, and pit crew are never on shift during ownTeam's matches. Matches may be short of scouts if too few are free
+func PlanShifts(schedule []db.ScheduledMatch, roster []string, rules db.ShiftRules, ownTeam int) map[string][]string {
+	shifts := make(map[string][]string)
+	pitCrew := make(map[string]bool)
+	for _, userid := range rules.PitCrew {
+		pitCrew[userid] = true
+	}
+	worked := make(map[string]int)
+	streak := make(map[string]int)
+	resting := make(map[string]int)
+	for _, match := range schedule {
+		ownMatch := contains(matchParticipants(match), ownTeam)
+		free := make([]string, 0)
+		for _, userid := range roster {
+			if resting[userid] > 0 || (ownMatch && pitCrew[userid]) {
+				continue
+			}
+			free = append(free, userid)
+		}
+		//stable, so scouts with the same workload keep roster order
+		sort.SliceStable(free, func(i, j int) bool {
+			return worked[free[i]] < worked[free[j]]
+		})
+		if len(free) > rules.ScoutsPerMatch {
+			free = free[:rules.ScoutsPerMatch]
+		}
+		onShift := make(map[string]bool)
+		for _, userid := range free {
+			onShift[userid] = true
+		}
+		for _, userid := range roster {
+			if !onShift[userid] {
+				streak[userid] = 0
+				if resting[userid] > 0 {
+					resting[userid]--
+				}
+				continue
+			}
+			worked[userid]++
+			streak[userid]++
+			if rules.MaxConsecutive > 0 && streak[userid] >= rules.MaxConsecutive {
+				streak[userid] = 0
+				resting[userid] = rules.BreakLength
+			}
+		}
+		//keep roster order within a match so exports are easy to read
+		shift := make([]string, 0, len(free))
+		for _, userid := range roster {
+			if onShift[userid] {
+				shift = append(shift, userid)
+			}
+		}
+		shifts[match.MatchID] = shift
+	}
+	return shifts
+}
 
-//writeSchedule serializes the schedule and writes it to the database
+//PlanPitScouting splits every competitor at a team's scheduled event, other than the team itself, among its pit scouts and saves the plan, replacing any earlier one. Every pit scout must be on the team.
+//Competitors come from the event's team list, so pits can be planned before the schedule is out, along with anyone on its schedule who isn't listed, such as at events which weren't imported
+func PlanPitScouting(teamID string, pitScouts []string) (map[int]string, error) {
+	if len(pitScouts) == 0 {
+		return nil, errors.New("no pit scouts were chosen")
+	}
+	members, err := db.GetTeamMembers(teamID)
+	if err != nil {
+		return nil, err
+	}
+	for _, userid := range pitScouts {
+		if !containsString(members, userid) {
+			return nil, fmt.Errorf("%q is not on the team", userid)
+		}
+	}
+	_, eventID, err := db.GetTeamSchedule(teamID)
+	if err != nil {
+		return nil, err
+	}
+	listed, err := db.GetEventTeams(eventID)
+	if err != nil {
+		return nil, err
+	}
+	schedule, err := db.GetEventSchedule(eventID)
+	if err != nil {
+		return nil, err
+	}
+	for _, match := range schedule {
+		listed = append(listed, matchParticipants(match)...)
+	}
+	ownTeam, _ := db.GetTeamNumber(teamID)
+	roster := make([]int, 0)
+	for _, team := range listed {
+		if team != ownTeam && !contains(roster, team) {
+			roster = append(roster, team)
+		}
+	}
+	//split in team number order, which is how pits are usually laid out
+	sort.Ints(roster)
+	plan := SplitPits(roster, pitScouts)
+	return plan, db.WritePitPlan(teamID, eventID, plan)
+}
+
+//SplitPits splits competitors among pit scouts in runs of neighbouring competitors, so each scout has as even a share as possible and walks as little as possible
+func SplitPits(roster []int, pitScouts []string) map[int]string {
+	plan := make(map[int]string, len(roster))
+	if len(pitScouts) == 0 {
+		return plan
+	}
+	for ind, team := range roster {
+		plan[team] = pitScouts[ind*len(pitScouts)/len(roster)]
+	}
+	return plan
+}
+
+//scheduleIndex finds a match in a schedule, or returns -1 if it isn't there
+func scheduleIndex(schedule []db.ScheduledMatch, matchID string) int {
+	for ind, match := range schedule {
+		if match.MatchID == matchID {
+			return ind
+		}
+	}
+	return -1
+}
+
+//matchParticipants returns match participants, red alliance first
+func matchParticipants(match db.ScheduledMatch) []int {
+	return append(append([]int{}, match.Red...), match.Blue...)
+}
+
+//pickScoutedTeam picks which team to scout based on priority and what teams are already being scouted.
+//Uncovered robots come first, highest priority first. Once every robot is covered, the robot with the most priority per scout is picked. Ties go to the robot listed first
+func pickScoutedTeam(robots []int, priorities, counts map[int]int) int {
+	toScout := 0
+	found := false
+	for _, team := range robots {
+		if counts[team] == 0 && (!found || priorities[team] > priorities[toScout]) {
+			toScout = team
+			found = true
+		}
+	}
+	if found {
+		return toScout
+	}
+	topPriority := -1.0
+	for _, team := range robots {
+		share := float64(priorities[team]) / float64(counts[team]+1)
+		if share > topPriority {
+			topPriority = share
+			toScout = team
+		}
+	}
+	return toScout
+}
 
-//matchParticpants returns match participants
+func contains(arr []int, val int) bool {
+	for _, x := range arr {
+		if x == val {
+			return true
+		}
+	}
+	return false
+}
 
-//pickScoutedTeam picks which team to scout based on priority and what teams are already being scouted
+func containsString(arr []string, val string) bool {
+	for _, x := range arr {
+		if x == val {
+			return true
+		}
+	}
+	return false
+}
+
+func sum(arr []int) int {
+	total := 0
+	for _, x := range arr {
+		total += x
+	}
+	return total
+}
@@ -0,0 +1,191 @@
+package scouting
+
+import (
+	"EPIC-Scouting/lib/db"
+	"EPIC-Scouting/lib/lumberjack"
+	"EPIC-Scouting/lib/tba"
+	"sort"
+	"sync"
+	"time"
+)
+
+//SyncInterval is how long the TBA sync waits between rounds. Set from TBASyncInterval in the configuration file
+var SyncInterval = 5 * time.Minute
+
+//syncMaxBackoff is the longest the TBA sync waits after repeated failures
+const syncMaxBackoff = time.Hour
+
+//SyncStatus is how syncing one event with TBA is going
+type SyncStatus struct {
+	EventKey    string
+	CampaignID  string
+	EventID     string
+	LastAttempt time.Time
+	LastSuccess time.Time
+	NextAttempt time.Time
+	Failures    int    // Failures in a row since the last success.
+	Error       string // Why the last attempt failed, if it did.
+	Stale       bool   // Whether the last sync used cached data because TBA couldn't be reached.
+}
+
+//syncState is what the TBA sync is doing, for the sysadmin page
+var syncState struct {
+	sync.Mutex
+	running  bool
+	stopped  string // Why the sync stopped, if it did.
+	failures int    // Rounds in a row in which TBA couldn't be reached.
+	events   map[string]*SyncStatus
+}
+
+//StartSync starts syncing the active event of every team with TBA in the background: the schedule, official results and rankings. It does nothing if the sync is already running
+func StartSync(client *tba.Client) {
+	syncState.Lock()
+	defer syncState.Unlock()
+	if syncState.running {
+		return
+	}
+	syncState.running = true
+	syncState.stopped = ""
+	syncState.failures = 0
+	syncState.events = make(map[string]*SyncStatus)
+	go runSync(client)
+}
+
+//SyncStatuses gets how syncing each event is going, ordered by event key, and why the sync stopped if it did
+func SyncStatuses() ([]SyncStatus, string) {
+	syncState.Lock()
+	defer syncState.Unlock()
+	statuses := make([]SyncStatus, 0, len(syncState.events))
+	for _, status := range syncState.events {
+		statuses = append(statuses, *status)
+	}
+	sort.Slice(statuses, func(i, j int) bool {
+		return statuses[i].EventKey < statuses[j].EventKey
+	})
+	return statuses, syncState.stopped
+}
+
+//runSync syncs in rounds until TBA rejects the auth key
+func runSync(client *tba.Client) {
+	log := lumberjack.New("Sync")
+	for {
+		_, err := client.GetStatus()
+		if tba.KeyRejected(err) {
+			log.Warnf("TBA sync stopped: %s", err.Error())
+			syncState.Lock()
+			syncState.running = false
+			syncState.stopped = err.Error()
+			syncState.Unlock()
+			return
+		}
+		syncState.Lock()
+		if err != nil {
+			//TBA is unreachable, so every event would fail
+			syncState.failures++
+		} else {
+			syncState.failures = 0
+		}
+		wait := backoff(syncState.failures)
+		syncState.Unlock()
+		if err == nil {
+			syncRound(client, log)
+		}
+		time.Sleep(wait)
+	}
+}
+
+//backoff gets how long to wait after some failures in a row, doubling with each one
+func backoff(failures int) time.Duration {
+	wait := SyncInterval
+	for ind := 0; ind < failures && wait < syncMaxBackoff; ind++ {
+		wait *= 2
+	}
+	if wait > syncMaxBackoff {
+		wait = syncMaxBackoff
+	}
+	return wait
+}
+
+//syncRound syncs every team's active event which is due, once each. Events no team is on any more are forgotten
+func syncRound(client *tba.Client, log *lumberjack.Lumberjack) {
+	active := make(map[string]SyncStatus)
+	for teamID := range db.TeamListFull() {
+		campaignID, eventID, err := db.GetTeamSchedule(teamID)
+		if err != nil {
+			continue
+		}
+		eventKey, _ := db.GetEventTBAKey(eventID)
+		if eventKey != "" {
+			active[eventID] = SyncStatus{EventKey: eventKey, CampaignID: campaignID, EventID: eventID}
+		}
+	}
+	syncState.Lock()
+	for eventID := range syncState.events {
+		if _, ok := active[eventID]; !ok {
+			delete(syncState.events, eventID)
+		}
+	}
+	due := make([]SyncStatus, 0, len(active))
+	for eventID, status := range active {
+		if current, ok := syncState.events[eventID]; ok {
+			status = *current
+		} else {
+			entry := status
+			syncState.events[eventID] = &entry
+		}
+		if !time.Now().Before(status.NextAttempt) {
+			due = append(due, status)
+		}
+	}
+	syncState.Unlock()
+	for _, status := range due {
+		err := syncEvent(client, status)
+		now := time.Now()
+		status.LastAttempt = now
+		status.Stale = err == tba.ErrStale
+		if err == nil || err == tba.ErrStale {
+			status.LastSuccess, status.Failures, status.Error = now, 0, ""
+		} else {
+			log.Warnf("Unable to sync %s with TBA: %s", status.EventKey, err.Error())
+			status.Failures++
+			status.Error = err.Error()
+		}
+		status.NextAttempt = now.Add(backoff(status.Failures))
+		syncState.Lock()
+		if entry, ok := syncState.events[status.EventID]; ok {
+			*entry = status
+		}
+		syncState.Unlock()
+	}
+}
+
+//syncEvent imports an event's schedule and official results again and stores its rankings. Returns ErrStale if any of it came from the cache
+func syncEvent(client *tba.Client, status SyncStatus) error {
+	_, stale := ImportEvent(client, status.CampaignID, status.EventKey)
+	if stale != nil && stale != tba.ErrStale {
+		return stale
+	}
+	rankings, err := client.GetEventRankings(status.EventKey)
+	if err == tba.ErrStale {
+		stale = err
+	} else if err != nil {
+		return err
+	}
+	stored := make([]db.Ranking, 0, len(rankings.Rankings))
+	for _, ranking := range rankings.Rankings {
+		team, err := tba.TeamNumber(ranking.TeamKey)
+		if err != nil {
+			return err
+		}
+		entry := db.Ranking{Team: team, Rank: ranking.Rank, Wins: ranking.Record.Wins, Losses: ranking.Record.Losses, Ties: ranking.Record.Ties, Played: ranking.MatchesPlayed}
+		if len(ranking.SortOrders) > 0 {
+			entry.RankingScore = ranking.SortOrders[0]
+		}
+		stored = append(stored, entry)
+	}
+	err = db.StoreRankings(status.EventID, stored)
+	if err != nil {
+		return err
+	}
+	return stale
+}
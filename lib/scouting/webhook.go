@@ -0,0 +1,155 @@
+package scouting
+
+import (
+	"EPIC-Scouting/lib/calc"
+	"EPIC-Scouting/lib/db"
+	"EPIC-Scouting/lib/lumberjack"
+	"EPIC-Scouting/lib/tba"
+	"fmt"
+	"net/http"
+	"sync"
+)
+
+//ReceiveWebhook checks the signature on a message TBA sent to the webhook, then handles it. It returns the HTTP status to answer TBA with, along with why the message wasn't handled.
+//Messages not signed with the configured webhook secret are refused with 401. The verification key TBA sends when the webhook is added is logged, to be entered on TBA's account page
+func ReceiveWebhook(client *tba.Client, body []byte, signature string) (int, error) {
+	message, err := tba.ReadWebhook(body, signature)
+	if err == tba.ErrNoWebhookSecret || err == tba.ErrBadSignature {
+		return http.StatusUnauthorized, err
+	}
+	if err != nil {
+		return http.StatusBadRequest, err
+	}
+	if message.MessageType == tba.MessageVerification {
+		var data tba.VerificationData
+		if message.Data(&data) == nil {
+			lumberjack.New("Webhook").Infof("TBA webhook verification key: %s", data.VerificationKey)
+		}
+		return http.StatusOK, nil
+	}
+	err = HandleWebhook(client, message)
+	if err != nil {
+		return http.StatusInternalServerError, fmt.Errorf("%s message: %s", message.MessageType, err.Error())
+	}
+	return http.StatusOK, nil
+}
+
+//HandleWebhook applies a message TBA sent to the webhook to every campaign its event was imported into. Messages about events which were never imported, and message types which aren't handled, are ignored.
+//Schedule changes are picked up by importing the event again with the client. That takes several requests to TBA, so it is queued to run in the background rather than holding up TBA's request
+func HandleWebhook(client *tba.Client, message tba.WebhookMessage) error {
+	switch message.MessageType {
+	case tba.MessageMatchScore:
+		var data tba.MatchScoreData
+		if err := message.Data(&data); err != nil {
+			return err
+		}
+		return storeMatchScore(data)
+	case tba.MessageUpcomingMatch:
+		var data tba.UpcomingMatchData
+		if err := message.Data(&data); err != nil {
+			return err
+		}
+		events, err := db.GetTBAEvents(data.EventKey)
+		if err != nil {
+			return err
+		}
+		for eventID := range events {
+			err = db.SetMatchTime(eventID, data.MatchKey, data.ScheduledTime)
+			if err != nil {
+				return err
+			}
+			calc.InvalidateEvent(eventID)
+		}
+	case tba.MessageScheduleUpdated:
+		var data tba.ScheduleUpdatedData
+		if err := message.Data(&data); err != nil {
+			return err
+		}
+		queueReimport(client, data.EventKey)
+	case tba.MessageAllianceSelection:
+		var data tba.AllianceSelectionData
+		if err := message.Data(&data); err != nil {
+			return err
+		}
+		queueReimport(client, data.EventKey)
+	}
+	return nil
+}
+
+//storeMatchScore updates a match and its official score breakdown from a match_score message
+func storeMatchScore(data tba.MatchScoreData) error {
+	events, err := db.GetTBAEvents(data.EventKey)
+	if err != nil {
+		return err
+	}
+	for eventID := range events {
+		err = storeMatch(eventID, data.Match)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+//reimports holds the events waiting to be imported again after webhook messages. One worker imports them in turn, so an event is never imported twice at once, and a burst of messages about an event only imports it once more
+var reimports = struct {
+	sync.Mutex
+	once    sync.Once
+	queue   chan reimport
+	pending map[string]bool // Event keys in the queue.
+	running sync.WaitGroup  // Reimports queued and not yet finished.
+}{queue: make(chan reimport, 64), pending: make(map[string]bool)}
+
+type reimport struct {
+	client   *tba.Client
+	eventKey string
+}
+
+//queueReimport queues an event to be imported again in the background, unless it is already waiting
+func queueReimport(client *tba.Client, eventKey string) {
+	reimports.once.Do(func() {
+		go runReimports()
+	})
+	reimports.Lock()
+	defer reimports.Unlock()
+	if reimports.pending[eventKey] {
+		return
+	}
+	select {
+	case reimports.queue <- reimport{client, eventKey}:
+		reimports.pending[eventKey] = true
+		reimports.running.Add(1)
+	default:
+		lumberjack.New("Webhook").Warnf("Unable to import event %s again: too many imports are waiting.", eventKey)
+	}
+}
+
+//runReimports imports queued events again as they arrive. An event is taken off the pending list before it is imported, so messages which arrive during the import queue it again
+func runReimports() {
+	log := lumberjack.New("Webhook")
+	for next := range reimports.queue {
+		reimports.Lock()
+		delete(reimports.pending, next.eventKey)
+		reimports.Unlock()
+		err := reimportEvent(next.client, next.eventKey)
+		if err != nil {
+			log.Errorf("Unable to import event %s again: %s", next.eventKey, err.Error())
+		}
+		reimports.running.Done()
+	}
+}
+
+//reimportEvent imports an event again into every campaign it was imported into
+func reimportEvent(client *tba.Client, eventKey string) error {
+	events, err := db.GetTBAEvents(eventKey)
+	if err != nil {
+		return err
+	}
+	for _, campaignID := range events {
+		_, err = ImportEvent(client, campaignID, eventKey)
+		if err != nil && err != tba.ErrStale {
+			return err
+		}
+	}
+	return nil
+}
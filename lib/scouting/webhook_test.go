@@ -0,0 +1,197 @@
+package scouting
+
+import (
+	"EPIC-Scouting/lib/db"
+	"EPIC-Scouting/lib/tba"
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+)
+
+const testSecret = "test-secret"
+
+//fixtures is where the recorded webhook messages are kept
+const fixtures = "../../doc/tba-webhooks"
+
+//fakeTBA serves an event from the recorded messages, with a schedule which can grow as if TBA had added matches
+type fakeTBA struct {
+	sync.Mutex
+	event   tba.Event
+	teams   []tba.Team
+	matches []tba.Match
+}
+
+func (fake *fakeTBA) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	fake.Lock()
+	defer fake.Unlock()
+	var v interface{}
+	switch r.URL.Path {
+	case "/event/2020okok":
+		v = fake.event
+	case "/event/2020okok/teams":
+		v = fake.teams
+	case "/event/2020okok/matches":
+		v = fake.matches
+	default:
+		http.NotFound(w, r)
+		return
+	}
+	json.NewEncoder(w).Encode(v)
+}
+
+//addMatch adds a qualification match to the fake event's schedule
+func (fake *fakeTBA) addMatch(number int) {
+	fake.Lock()
+	defer fake.Unlock()
+	match := tba.Match{Key: fmt.Sprintf("2020okok_qm%v", number), CompLevel: "qm", SetNumber: 1, MatchNumber: number, EventKey: "2020okok"}
+	match.Alliances.Red.TeamKeys = []string{"frc4415", "frc2848", "frc3847"}
+	match.Alliances.Blue.TeamKeys = []string{"frc5431", "frc1758", "frc6059"}
+	fake.matches = append(fake.matches, match)
+}
+
+//readFixture reads a recorded webhook message
+func readFixture(t *testing.T, name string) []byte {
+	body, err := ioutil.ReadFile(filepath.Join(fixtures, name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return body
+}
+
+//sign signs a webhook message the way TBA does
+func sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+//webhookServer accepts webhook messages the same way the server's route does. See ReceiveWebhook
+func webhookServer(client *tba.Client) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		status, err := ReceiveWebhook(client, body, r.Header.Get(tba.HMACHeader))
+		if err != nil {
+			http.Error(w, err.Error(), status)
+			return
+		}
+		w.WriteHeader(status)
+	}))
+}
+
+//send posts a body to the webhook with a signature, and returns the status code
+func send(t *testing.T, url string, body []byte, signature string) int {
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set(tba.HMACHeader, signature)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	return resp.StatusCode
+}
+
+func TestWebhookFixtures(t *testing.T) {
+	dir, err := ioutil.TempDir("", "webhook-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	db.TouchBase(dir + "/")
+	campaigns, err := sql.Open("sqlite3", filepath.Join(dir, "campaigns.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer campaigns.Close()
+
+	var selection struct {
+		MessageData tba.AllianceSelectionData `json:"message_data"`
+	}
+	if err := json.Unmarshal(readFixture(t, "alliance_selection.json"), &selection); err != nil {
+		t.Fatal(err)
+	}
+	fake := &fakeTBA{event: selection.MessageData.Event}
+	for _, team := range []int{4415, 2848, 3847, 5431, 1758, 6059} {
+		fake.teams = append(fake.teams, tba.Team{Key: tba.TeamKey(team), TeamNumber: team})
+	}
+	fake.addMatch(1)
+	fake.addMatch(2)
+	tbaServer := httptest.NewServer(fake)
+	defer tbaServer.Close()
+	client := tba.NewClient("test-key")
+	client.BaseURL = tbaServer.URL
+
+	db.CampaignCreate("test", "test", "Webhook Test")
+	var campaignID string
+	for id, campaign := range db.CampaignList() {
+		if campaign[1] == "Webhook Test" {
+			campaignID = id
+		}
+	}
+	eventID, err := ImportEvent(client, campaignID, "2020okok")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tba.WebhookSecret = testSecret
+	defer func() { tba.WebhookSecret = "" }()
+	webhook := webhookServer(client)
+	defer webhook.Close()
+
+	for _, name := range []string{"verification.json", "match_score.json", "upcoming_match.json"} {
+		body := readFixture(t, name)
+		if status := send(t, webhook.URL, body, sign(testSecret, body)); status != http.StatusOK {
+			t.Errorf("%s: got %v", name, status)
+		}
+	}
+	var matchID string
+	campaigns.QueryRow(fmt.Sprintf("SELECT matchid FROM matches WHERE eventid='%s' AND tbakey='2020okok_qm1'", eventID)).Scan(&matchID)
+	scores, err := db.GetOfficialScores(matchID)
+	if err != nil || len(scores) != 2 {
+		t.Errorf("match_score: got %+v, %v", scores, err)
+	}
+	var start int64
+	campaigns.QueryRow(fmt.Sprintf("SELECT time FROM matches WHERE eventid='%s' AND tbakey='2020okok_qm2'", eventID)).Scan(&start)
+	if start != 1583417400 {
+		t.Errorf("upcoming_match: match time is %v", start)
+	}
+
+	//schedule changes import the event again in the background
+	fake.addMatch(3)
+	for _, name := range []string{"schedule_updated.json", "alliance_selection.json"} {
+		body := readFixture(t, name)
+		if status := send(t, webhook.URL, body, sign(testSecret, body)); status != http.StatusOK {
+			t.Errorf("%s: got %v", name, status)
+		}
+	}
+	reimports.running.Wait()
+	schedule, _ := db.GetEventSchedule(eventID)
+	if len(schedule) != 3 {
+		t.Errorf("schedule_updated: %v matches are scheduled", len(schedule))
+	}
+
+	body := readFixture(t, "match_score.json")
+	if status := send(t, webhook.URL, body, sign("wrong-secret", body)); status != http.StatusUnauthorized {
+		t.Errorf("wrong secret: got %v", status)
+	}
+	if status := send(t, webhook.URL, body, ""); status != http.StatusUnauthorized {
+		t.Errorf("no signature: got %v", status)
+	}
+	tba.WebhookSecret = ""
+	if status := send(t, webhook.URL, body, sign(testSecret, body)); status != http.StatusUnauthorized {
+		t.Errorf("no secret configured: got %v", status)
+	}
+}
@@ -5,73 +5,318 @@ See https://www.thebluealliance.com/apidocs for more information.
 package tba
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io/ioutil"
 	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
 )
 
-// Putting authentication key variable here until it can get connected to the config file.
-// All request functions return a Response struct. Docs can be found at https://golang.org/src/net/http/response.go.
-const tba string = "https://www.thebluealliance.com/api/v3"
-const keyfield string = "X-TBA-Auth-Key="
-const keystring string = "?X-TBA-Auth-Key=eSMjxo253BoTGFoaeZteq7wF1pGLGnZw24aaHxfvfsvF7VvNaTLOf7ZlvhbbJQxs"
+//DefaultBaseURL is the root of version 3 of The Blue Alliance's API
+const DefaultBaseURL string = "https://www.thebluealliance.com/api/v3"
 
-var enabled bool
+//authHeader is the header TBA reads the auth key from
+const authHeader string = "X-TBA-Auth-Key"
 
-func httpGet(url, dir, querystring string) *http.Response {
-	resp, err := http.Get(fmt.Sprintf("%s%s%s", tba, dir, querystring))
-	// Return nil if GET fails.
-	if err == nil {
-		return resp
+//ErrNoAuthKey is returned instead of making a request when a client has no auth key
+var ErrNoAuthKey = errors.New("no TBA auth key is configured")
+
+//ErrStale is returned along with a cached response when TBA couldn't be reached. The data is whatever TBA last sent, so it may be out of date
+var ErrStale = errors.New("TBA could not be reached, so cached data was used")
+
+//Client makes requests to The Blue Alliance's API
+type Client struct {
+	BaseURL    string       // Usually DefaultBaseURL. Point it at a local server to test without reaching TBA.
+	AuthKey    string       // Sent with every request as the X-TBA-Auth-Key header.
+	HTTPClient *http.Client // Nil uses a client which gives up after 10 seconds.
+	CacheDir   string       // Where responses are kept to make conditional requests and to fall back on when offline. Empty turns caching off.
+}
+
+//DefaultClient is the client the package's functions use. Its auth key is set from TBAAuthKey in the configuration file at startup
+var DefaultClient = NewClient("")
+
+//NewClient makes a client for the real API
+func NewClient(authKey string) *Client {
+	return &Client{BaseURL: DefaultBaseURL, AuthKey: authKey, HTTPClient: &http.Client{Timeout: 10 * time.Second}}
+}
+
+//Error is a response from TBA which wasn't successful
+type Error struct {
+	StatusCode int
+	Path       string
+	Message    string // TBA's own explanation, if it gave one.
+}
+
+func (err *Error) Error() string {
+	if err.Message == "" {
+		return fmt.Sprintf("TBA request for %s failed: %v %s", err.Path, err.StatusCode, http.StatusText(err.StatusCode))
 	}
-	return nil
+	return fmt.Sprintf("TBA request for %s failed: %v %s: %s", err.Path, err.StatusCode, http.StatusText(err.StatusCode), err.Message)
 }
 
-//KeyIsWorking checks if error code status is okay, which indicates the auth key is valid
-func KeyIsWorking() bool {
-	if GetStatus().Status == "200 OK" {
+//get requests a path from the API and decodes the JSON response into v. Keys in the path should be escaped with url.PathEscape, since admins type them in.
+//Responses are cached with their ETag and Last-Modified headers, which are sent back so TBA only resends data that has changed. If TBA can't be reached or is having trouble, the cached response is decoded instead and ErrStale is returned
+func (client *Client) get(path string, v interface{}) error {
+	if client.AuthKey == "" {
+		return ErrNoAuthKey
+	}
+	cached, haveCached := client.readCache(path)
+	body, err := client.fetch(path, cached, haveCached)
+	if err != nil {
+		if tbaErr, ok := err.(*Error); (!ok || tbaErr.StatusCode >= 500) && haveCached {
+			body = cached.Body
+			err = ErrStale
+		} else {
+			return err
+		}
+	}
+	if jsonErr := json.Unmarshal(body, v); jsonErr != nil {
+		return fmt.Errorf("TBA response for %s could not be read: %s", path, jsonErr.Error())
+	}
+	return err
+}
+
+//fetch makes a conditional request for a path, returning the cached body if TBA says it hasn't changed
+func (client *Client) fetch(path string, cached cacheEntry, haveCached bool) ([]byte, error) {
+	httpClient := client.HTTPClient
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: 10 * time.Second}
+	}
+	req, err := http.NewRequest(http.MethodGet, strings.TrimSuffix(client.BaseURL, "/")+path, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set(authHeader, client.AuthKey)
+	if haveCached && cached.ETag != "" {
+		req.Header.Set("If-None-Match", cached.ETag)
+	}
+	if haveCached && cached.LastModified != "" {
+		req.Header.Set("If-Modified-Since", cached.LastModified)
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("TBA request for %s failed: %s", path, err.Error())
+	}
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("TBA request for %s failed: %s", path, err.Error())
+	}
+	if resp.StatusCode == http.StatusNotModified && haveCached {
+		return cached.Body, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, &Error{StatusCode: resp.StatusCode, Path: path, Message: errorMessage(body)}
+	}
+	client.writeCache(path, cacheEntry{ETag: resp.Header.Get("ETag"), LastModified: resp.Header.Get("Last-Modified"), Fetched: time.Now(), Body: body})
+	return body, nil
+}
+
+//cacheEntry is a response kept in a client's CacheDir
+type cacheEntry struct {
+	ETag         string
+	LastModified string
+	Fetched      time.Time
+	Body         json.RawMessage
+}
+
+//unsafeFileName matches everything which shouldn't be in a cache file name
+var unsafeFileName = regexp.MustCompile("[^A-Za-z0-9_-]+")
+
+//cacheFile gets the file a path's response is cached in
+func (client *Client) cacheFile(path string) string {
+	return filepath.Join(client.CacheDir, unsafeFileName.ReplaceAllString(strings.Trim(path, "/"), "_")+".json")
+}
+
+//readCache gets a path's cached response, if caching is on and there is one
+func (client *Client) readCache(path string) (cacheEntry, bool) {
+	var entry cacheEntry
+	if client.CacheDir == "" {
+		return entry, false
+	}
+	data, err := ioutil.ReadFile(client.cacheFile(path))
+	if err != nil || json.Unmarshal(data, &entry) != nil {
+		return entry, false
+	}
+	return entry, true
+}
+
+//writeCache keeps a path's response. The file is replaced in one step so a crash can't leave half a response behind. Failures only cost a cache miss later, so they are ignored
+func (client *Client) writeCache(path string, entry cacheEntry) {
+	if client.CacheDir == "" {
+		return
+	}
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return
+	}
+	if os.MkdirAll(client.CacheDir, 0755) != nil {
+		return
+	}
+	tmp, err := ioutil.TempFile(client.CacheDir, "partial-")
+	if err != nil {
+		return
+	}
+	_, err = tmp.Write(data)
+	tmp.Close()
+	if err != nil || os.Rename(tmp.Name(), client.cacheFile(path)) != nil {
+		os.Remove(tmp.Name())
+	}
+}
+
+//errorMessage pulls TBA's explanation out of an error response, which is either {"Error": "..."} or {"Errors": [{"field": "..."}]}
+func errorMessage(body []byte) string {
+	var message struct {
+		Error  string              `json:"Error"`
+		Errors []map[string]string `json:"Errors"`
+	}
+	if json.Unmarshal(body, &message) != nil {
+		return ""
+	}
+	messages := make([]string, 0)
+	if message.Error != "" {
+		messages = append(messages, message.Error)
+	}
+	for _, errs := range message.Errors {
+		for _, text := range errs {
+			messages = append(messages, text)
+		}
+	}
+	return strings.Join(messages, "; ")
+}
+
+//TeamKey gets TBA's key for a team number, such as frc4415
+func TeamKey(number int) string {
+	return fmt.Sprintf("frc%v", number)
+}
+
+//TeamNumber gets the team number from one of TBA's team keys
+func TeamNumber(key string) (int, error) {
+	if !strings.HasPrefix(key, "frc") {
+		return 0, fmt.Errorf("%q is not a team key", key)
+	}
+	return strconv.Atoi(strings.TrimPrefix(key, "frc"))
+}
+
+//KeyIsWorking checks that the client has an auth key and TBA accepts it. If TBA can't be reached, or is having trouble, the key is not blamed
+func (client *Client) KeyIsWorking() bool {
+	_, err := client.GetStatus()
+	return !KeyRejected(err)
+}
+
+//KeyRejected reports whether a request failed because the client has no auth key or TBA refused the one it has
+func KeyRejected(err error) bool {
+	if err == ErrNoAuthKey {
 		return true
 	}
-	return false
+	tbaErr, ok := err.(*Error)
+	return ok && (tbaErr.StatusCode == http.StatusUnauthorized || tbaErr.StatusCode == http.StatusForbidden)
 }
 
 //GetStatus - Gets TBA API Status
-func GetStatus() *http.Response {
-	dir := "/status"
-	resp := httpGet(tba, dir, keystring)
-	return resp
+func (client *Client) GetStatus() (Status, error) {
+	var status Status
+	err := client.get("/status", &status)
+	return status, err
+}
+
+//GetEvent gets an event's details
+func (client *Client) GetEvent(event string) (Event, error) {
+	var e Event
+	err := client.get(fmt.Sprintf("/event/%s", url.PathEscape(event)), &e)
+	return e, err
 }
 
 //GetMatch gets match data including participating teams and results
-func GetMatch(match string) *http.Response {
-	dir := fmt.Sprintf("/match/%s", match)
-	resp := httpGet(tba, dir, keystring)
-	return resp
+func (client *Client) GetMatch(match string) (Match, error) {
+	var m Match
+	err := client.get(fmt.Sprintf("/match/%s", url.PathEscape(match)), &m)
+	return m, err
 }
 
 //GetEventTeams gets which teams are at an event
-func GetEventTeams(event string) *http.Response {
-	dir := fmt.Sprintf("/event/%s/teams", event)
-	resp := httpGet(tba, dir, keystring)
-	return resp
+func (client *Client) GetEventTeams(event string) ([]Team, error) {
+	teams := make([]Team, 0)
+	err := client.get(fmt.Sprintf("/event/%s/teams", url.PathEscape(event)), &teams)
+	return teams, err
 }
 
 //GetEventMatches gets a list of matches at an event
-func GetEventMatches(event string) *http.Response {
-	dir := fmt.Sprintf("/event/%s/matches", event)
-	resp := httpGet(tba, dir, keystring)
-	return resp
+func (client *Client) GetEventMatches(event string) ([]Match, error) {
+	matches := make([]Match, 0)
+	err := client.get(fmt.Sprintf("/event/%s/matches", url.PathEscape(event)), &matches)
+	return matches, err
+}
+
+//GetEventRankings gets the qualification rankings at an event. There are none until its first matches are played
+func (client *Client) GetEventRankings(event string) (EventRankings, error) {
+	var rankings EventRankings
+	err := client.get(fmt.Sprintf("/event/%s/rankings", url.PathEscape(event)), &rankings)
+	return rankings, err
 }
 
-//GetTeamMatches gets all matches a team was involved in
-func GetTeamMatches(team, year string) *http.Response {
-	dir := fmt.Sprintf("/team/%s/matches%s", team, year)
-	resp := httpGet(tba, dir, keystring)
-	return resp
+//GetTeamMatches gets all matches a team was involved in during a year
+func (client *Client) GetTeamMatches(team string, year int) ([]Match, error) {
+	matches := make([]Match, 0)
+	err := client.get(fmt.Sprintf("/team/%s/matches/%v", url.PathEscape(team), year), &matches)
+	return matches, err
 }
 
 //GetTeamEvents gets all events a team was involved in
-func GetTeamEvents(team string) *http.Response {
-	dir := fmt.Sprintf("/team/%s/events", team)
-	resp := httpGet(tba, dir, keystring)
-	return resp
+func (client *Client) GetTeamEvents(team string) ([]Event, error) {
+	events := make([]Event, 0)
+	err := client.get(fmt.Sprintf("/team/%s/events", url.PathEscape(team)), &events)
+	return events, err
+}
+
+//KeyIsWorking checks if the configured auth key is accepted. See Client.KeyIsWorking
+func KeyIsWorking() bool {
+	return DefaultClient.KeyIsWorking()
+}
+
+//GetStatus - Gets TBA API Status. See Client.GetStatus
+func GetStatus() (Status, error) {
+	return DefaultClient.GetStatus()
+}
+
+//GetEvent gets an event's details. See Client.GetEvent
+func GetEvent(event string) (Event, error) {
+	return DefaultClient.GetEvent(event)
+}
+
+//GetMatch gets match data including participating teams and results. See Client.GetMatch
+func GetMatch(match string) (Match, error) {
+	return DefaultClient.GetMatch(match)
+}
+
+//GetEventTeams gets which teams are at an event. See Client.GetEventTeams
+func GetEventTeams(event string) ([]Team, error) {
+	return DefaultClient.GetEventTeams(event)
+}
+
+//GetEventMatches gets a list of matches at an event. See Client.GetEventMatches
+func GetEventMatches(event string) ([]Match, error) {
+	return DefaultClient.GetEventMatches(event)
+}
+
+//GetEventRankings gets the qualification rankings at an event. See Client.GetEventRankings
+func GetEventRankings(event string) (EventRankings, error) {
+	return DefaultClient.GetEventRankings(event)
+}
+
+//GetTeamMatches gets all matches a team was involved in during a year. See Client.GetTeamMatches
+func GetTeamMatches(team string, year int) ([]Match, error) {
+	return DefaultClient.GetTeamMatches(team, year)
+}
+
+//GetTeamEvents gets all events a team was involved in. See Client.GetTeamEvents
+func GetTeamEvents(team string) ([]Event, error) {
+	return DefaultClient.GetTeamEvents(team)
 }
@@ -0,0 +1,170 @@
+package tba
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+)
+
+const testEvent = `{"key": "2020okok", "name": "Green Country Regional", "year": 2020}`
+
+//testClient makes a client for a local server which caches responses in a fresh directory
+func testClient(t *testing.T, handler http.HandlerFunc) (*Client, *httptest.Server) {
+	server := httptest.NewServer(handler)
+	dir, err := ioutil.TempDir("", "tba-cache-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		server.Close()
+		os.RemoveAll(dir)
+	})
+	client := NewClient("test-key")
+	client.BaseURL = server.URL
+	client.CacheDir = dir
+	return client, server
+}
+
+func TestGetOK(t *testing.T) {
+	client, _ := testClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get(authHeader) != "test-key" {
+			t.Errorf("auth key sent as %q", r.Header.Get(authHeader))
+		}
+		if r.URL.Path != "/event/2020okok" {
+			t.Errorf("requested %s", r.URL.Path)
+		}
+		w.Header().Set("ETag", `"v1"`)
+		fmt.Fprint(w, testEvent)
+	})
+	event, err := client.GetEvent("2020okok")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if event.Name != "Green Country Regional" || event.Year != 2020 {
+		t.Errorf("got %+v", event)
+	}
+	cached, ok := client.readCache("/event/2020okok")
+	if !ok || cached.ETag != `"v1"` {
+		t.Errorf("response was not cached with its ETag: %+v", cached)
+	}
+}
+
+func TestGetNotModified(t *testing.T) {
+	requests := 0
+	client, _ := testClient(t, func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", `"v1"`)
+		fmt.Fprint(w, testEvent)
+	})
+	if _, err := client.GetEvent("2020okok"); err != nil {
+		t.Fatal(err)
+	}
+	event, err := client.GetEvent("2020okok")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if requests != 2 || event.Key != "2020okok" {
+		t.Errorf("got %+v after %v requests", event, requests)
+	}
+}
+
+func TestGetUnauthorized(t *testing.T) {
+	client, _ := testClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+		fmt.Fprint(w, `{"Error": "X-TBA-Auth-Key is invalid. Please get an access key at http://www.thebluealliance.com/account."}`)
+	})
+	//a rejected key is never papered over with cached data
+	client.writeCache("/event/2020okok", cacheEntry{Body: []byte(testEvent)})
+	_, err := client.GetEvent("2020okok")
+	tbaErr, ok := err.(*Error)
+	if !ok || tbaErr.StatusCode != http.StatusUnauthorized || tbaErr.Message == "" {
+		t.Errorf("got %v", err)
+	}
+}
+
+func TestGetServerErrorUsesCache(t *testing.T) {
+	client, _ := testClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	})
+	_, err := client.GetEvent("2020okok")
+	if tbaErr, ok := err.(*Error); !ok || tbaErr.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("got %v without a cache entry", err)
+	}
+	client.writeCache("/event/2020okok", cacheEntry{Body: []byte(testEvent)})
+	event, err := client.GetEvent("2020okok")
+	if err != ErrStale || event.Key != "2020okok" {
+		t.Errorf("got %+v, %v with a cache entry", event, err)
+	}
+}
+
+func TestGetNetworkDown(t *testing.T) {
+	client, server := testClient(t, func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, testEvent)
+	})
+	if _, err := client.GetEvent("2020okok"); err != nil {
+		t.Fatal(err)
+	}
+	server.Close()
+	event, err := client.GetEvent("2020okok")
+	if err != ErrStale || event.Key != "2020okok" {
+		t.Errorf("got %+v, %v for a cached event", event, err)
+	}
+	_, err = client.GetEventTeams("2020okok")
+	if err == nil || err == ErrStale {
+		t.Errorf("got %v for an uncached request", err)
+	}
+}
+
+func TestGetNoAuthKey(t *testing.T) {
+	client, _ := testClient(t, func(w http.ResponseWriter, r *http.Request) {
+		t.Error("a request was made without an auth key")
+	})
+	client.AuthKey = ""
+	if _, err := client.GetStatus(); err != ErrNoAuthKey {
+		t.Errorf("got %v", err)
+	}
+}
+
+func TestKeysAreEscaped(t *testing.T) {
+	client, _ := testClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.EscapedPath() != "/event/2020okok%2F..%2Fstatus%3F/teams" {
+			t.Errorf("requested %s", r.URL.EscapedPath())
+		}
+		fmt.Fprint(w, "[]")
+	})
+	if _, err := client.GetEventTeams("2020okok/../status?"); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestKeyIsWorking(t *testing.T) {
+	status := http.StatusOK
+	client, server := testClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(status)
+		fmt.Fprint(w, `{"current_season": 2020}`)
+	})
+	if !client.KeyIsWorking() {
+		t.Error("an accepted key is reported as broken")
+	}
+	status = http.StatusUnauthorized
+	if client.KeyIsWorking() {
+		t.Error("a rejected key is reported as working")
+	}
+	//TBA being unreachable is not the key's fault, even with a cached status to fall back on
+	status = http.StatusOK
+	client.GetStatus()
+	server.Close()
+	if _, err := client.GetStatus(); err != ErrStale {
+		t.Fatalf("got %v without TBA", err)
+	}
+	if !client.KeyIsWorking() {
+		t.Error("the key is reported as broken while TBA can't be reached")
+	}
+}
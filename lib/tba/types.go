@@ -0,0 +1,158 @@
+package tba
+
+import (
+	"fmt"
+	"time"
+)
+
+//Status is the state of TBA's API
+type Status struct {
+	CurrentSeason  int      `json:"current_season"`
+	MaxSeason      int      `json:"max_season"`
+	IsDatafeedDown bool     `json:"is_datafeed_down"`
+	DownEvents     []string `json:"down_events"` // Keys of events whose data isn't being updated.
+}
+
+//Team is a team as TBA describes it
+type Team struct {
+	Key        string `json:"key"` // Such as frc4415. See TeamKey.
+	TeamNumber int    `json:"team_number"`
+	Nickname   string `json:"nickname"`
+	Name       string `json:"name"` // The team's full name, usually a list of sponsors.
+	City       string `json:"city"`
+	StateProv  string `json:"state_prov"`
+	Country    string `json:"country"`
+	RookieYear int    `json:"rookie_year"`
+}
+
+//Event is an event as TBA describes it
+type Event struct {
+	Key       string `json:"key"` // Such as 2020okok.
+	Name      string `json:"name"`
+	EventCode string `json:"event_code"`
+	EventType int    `json:"event_type"`
+	City      string `json:"city"`
+	StateProv string `json:"state_prov"`
+	Country   string `json:"country"`
+	StartDate string `json:"start_date"` // YYYY-MM-DD, in the event's time zone.
+	EndDate   string `json:"end_date"`   // YYYY-MM-DD, in the event's time zone.
+	Year      int    `json:"year"`
+	Timezone  string `json:"timezone"`
+}
+
+//Times gets when an event starts and ends. The end is the end of its last day. Dates are read in the event's time zone when TBA gives one
+func (event Event) Times() (time.Time, time.Time, error) {
+	location := time.UTC
+	if event.Timezone != "" {
+		if loc, err := time.LoadLocation(event.Timezone); err == nil {
+			location = loc
+		}
+	}
+	start, err := time.ParseInLocation("2006-01-02", event.StartDate, location)
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("event %s has no start date: %s", event.Key, err.Error())
+	}
+	end, err := time.ParseInLocation("2006-01-02", event.EndDate, location)
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("event %s has no end date: %s", event.Key, err.Error())
+	}
+	return start, end.AddDate(0, 0, 1), nil
+}
+
+//Match is a match as TBA describes it
+type Match struct {
+	Key             string          `json:"key"`        // Such as 2020okok_qm12.
+	CompLevel       string          `json:"comp_level"` // qm, ef, qf, sf or f.
+	SetNumber       int             `json:"set_number"`
+	MatchNumber     int             `json:"match_number"`
+	EventKey        string          `json:"event_key"`
+	Alliances       Alliances       `json:"alliances"`
+	WinningAlliance string          `json:"winning_alliance"` // red, blue, or empty for a tie or unplayed match.
+	Time            int64           `json:"time"`             // Scheduled start, in Unix time.
+	PredictedTime   int64           `json:"predicted_time"`
+	ActualTime      int64           `json:"actual_time"`     // 0 until the match is played.
+	ScoreBreakdown  *ScoreBreakdown `json:"score_breakdown"` // Nil until the match is played.
+}
+
+//Alliances are both alliances in a match
+type Alliances struct {
+	Red  MatchAlliance `json:"red"`
+	Blue MatchAlliance `json:"blue"`
+}
+
+//MatchAlliance is one alliance in a match
+type MatchAlliance struct {
+	Score             int      `json:"score"`     // -1 until the match is played.
+	TeamKeys          []string `json:"team_keys"` // In station order.
+	SurrogateTeamKeys []string `json:"surrogate_team_keys"`
+	DQTeamKeys        []string `json:"dq_team_keys"`
+}
+
+//ScoreBreakdown is how each alliance earned its score in a match
+type ScoreBreakdown struct {
+	Red  AllianceBreakdown `json:"red"`
+	Blue AllianceBreakdown `json:"blue"`
+}
+
+//AllianceBreakdown is how an alliance earned its score in a 2020 match
+type AllianceBreakdown struct {
+	InitLineRobot1                string `json:"initLineRobot1"` // Exited or None.
+	InitLineRobot2                string `json:"initLineRobot2"`
+	InitLineRobot3                string `json:"initLineRobot3"`
+	EndgameRobot1                 string `json:"endgameRobot1"` // Hang, Park or None.
+	EndgameRobot2                 string `json:"endgameRobot2"`
+	EndgameRobot3                 string `json:"endgameRobot3"`
+	AutoCellsBottom               int    `json:"autoCellsBottom"`
+	AutoCellsOuter                int    `json:"autoCellsOuter"`
+	AutoCellsInner                int    `json:"autoCellsInner"`
+	TeleopCellsBottom             int    `json:"teleopCellsBottom"`
+	TeleopCellsOuter              int    `json:"teleopCellsOuter"`
+	TeleopCellsInner              int    `json:"teleopCellsInner"`
+	Stage1Activated               bool   `json:"stage1Activated"`
+	Stage2Activated               bool   `json:"stage2Activated"`
+	Stage3Activated               bool   `json:"stage3Activated"`
+	EndgameRungIsLevel            string `json:"endgameRungIsLevel"` // IsLevel or NotLevel.
+	AutoInitLinePoints            int    `json:"autoInitLinePoints"`
+	AutoCellPoints                int    `json:"autoCellPoints"`
+	AutoPoints                    int    `json:"autoPoints"`
+	TeleopCellPoints              int    `json:"teleopCellPoints"`
+	ControlPanelPoints            int    `json:"controlPanelPoints"`
+	EndgamePoints                 int    `json:"endgamePoints"`
+	TeleopPoints                  int    `json:"teleopPoints"`
+	ShieldOperationalRankingPoint bool   `json:"shieldOperationalRankingPoint"`
+	ShieldEnergizedRankingPoint   bool   `json:"shieldEnergizedRankingPoint"`
+	FoulCount                     int    `json:"foulCount"`
+	TechFoulCount                 int    `json:"techFoulCount"`
+	FoulPoints                    int    `json:"foulPoints"`
+	TotalPoints                   int    `json:"totalPoints"`
+	RP                            int    `json:"rp"`
+}
+
+//EventRankings are the qualification rankings at an event
+type EventRankings struct {
+	Rankings      []Ranking       `json:"rankings"`
+	SortOrderInfo []SortOrderInfo `json:"sort_order_info"` // What each of a ranking's SortOrders is.
+}
+
+//Ranking is a team's place in an event's qualification rankings
+type Ranking struct {
+	TeamKey       string    `json:"team_key"`
+	Rank          int       `json:"rank"`
+	MatchesPlayed int       `json:"matches_played"`
+	Record        Record    `json:"record"`
+	DQ            int       `json:"dq"`          // Matches the team was disqualified from.
+	SortOrders    []float64 `json:"sort_orders"` // The values teams are ranked by, in order. The first is the ranking score.
+}
+
+//Record is a team's wins, losses and ties
+type Record struct {
+	Wins   int `json:"wins"`
+	Losses int `json:"losses"`
+	Ties   int `json:"ties"`
+}
+
+//SortOrderInfo names one of the values teams are ranked by
+type SortOrderInfo struct {
+	Name      string `json:"name"`
+	Precision int    `json:"precision"`
+}
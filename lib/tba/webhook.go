@@ -0,0 +1,114 @@
+package tba
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+//HMACHeader is the header TBA signs webhook messages in
+const HMACHeader string = "X-TBA-HMAC"
+
+//WebhookSecret is the secret webhook messages are signed with, as given to TBA when the webhook was added to its account page. It is set from TBAWebhookSecret in the configuration file at startup
+var WebhookSecret string
+
+//ErrNoWebhookSecret is returned instead of accepting a webhook message when no secret is configured
+var ErrNoWebhookSecret = errors.New("no TBA webhook secret is configured")
+
+//ErrBadSignature is returned for a webhook message which wasn't signed with the configured secret
+var ErrBadSignature = errors.New("TBA webhook signature does not match")
+
+//Message types TBA sends to webhooks which are handled
+const (
+	MessageVerification      = "verification"
+	MessageMatchScore        = "match_score"
+	MessageUpcomingMatch     = "upcoming_match"
+	MessageScheduleUpdated   = "schedule_updated"
+	MessageAllianceSelection = "alliance_selection"
+)
+
+//WebhookMessage is a message TBA sent to a webhook. MessageData is read with the Data type for its MessageType
+type WebhookMessage struct {
+	MessageType string          `json:"message_type"`
+	MessageData json.RawMessage `json:"message_data"`
+}
+
+//VerificationData is sent when a webhook is added. The key has to be entered on TBA's account page before any other messages are sent
+type VerificationData struct {
+	VerificationKey string `json:"verification_key"`
+}
+
+//MatchScoreData is sent when a match's score is posted or changed
+type MatchScoreData struct {
+	EventKey  string `json:"event_key"`
+	MatchKey  string `json:"match_key"`
+	EventName string `json:"event_name"`
+	Match     Match  `json:"match"`
+}
+
+//UpcomingMatchData is sent shortly before a match starts
+type UpcomingMatchData struct {
+	EventKey      string   `json:"event_key"`
+	MatchKey      string   `json:"match_key"`
+	EventName     string   `json:"event_name"`
+	TeamKeys      []string `json:"team_keys"`
+	ScheduledTime int64    `json:"scheduled_time"` // Unix time.
+	PredictedTime int64    `json:"predicted_time"`
+}
+
+//ScheduleUpdatedData is sent when matches are added to an event's schedule, such as after alliance selection
+type ScheduleUpdatedData struct {
+	EventKey       string `json:"event_key"`
+	EventName      string `json:"event_name"`
+	FirstMatchTime int64  `json:"first_match_time"`
+}
+
+//AllianceSelectionData is sent when an event's playoff alliances are picked
+type AllianceSelectionData struct {
+	EventKey  string `json:"event_key"`
+	EventName string `json:"event_name"`
+	Event     Event  `json:"event"`
+}
+
+//VerifyWebhook checks that a webhook message's body was signed with the secret. The signature is the hex HMAC-SHA256 TBA sends in the X-TBA-HMAC header
+func VerifyWebhook(secret string, body []byte, signature string) error {
+	if secret == "" {
+		return ErrNoWebhookSecret
+	}
+	given, err := hex.DecodeString(signature)
+	if err != nil {
+		return ErrBadSignature
+	}
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	if !hmac.Equal(given, mac.Sum(nil)) {
+		return ErrBadSignature
+	}
+	return nil
+}
+
+//ReadWebhook verifies a webhook message against WebhookSecret and decodes it
+func ReadWebhook(body []byte, signature string) (WebhookMessage, error) {
+	var message WebhookMessage
+	err := VerifyWebhook(WebhookSecret, body, signature)
+	if err != nil {
+		return message, err
+	}
+	err = json.Unmarshal(body, &message)
+	if err != nil {
+		return message, fmt.Errorf("TBA webhook message could not be read: %s", err.Error())
+	}
+	return message, nil
+}
+
+//Data decodes a webhook message's data into v, which should be the Data type for its MessageType
+func (message WebhookMessage) Data(v interface{}) error {
+	err := json.Unmarshal(message.MessageData, v)
+	if err != nil {
+		return fmt.Errorf("TBA %s message could not be read: %s", message.MessageType, err.Error())
+	}
+	return nil
+}
@@ -1,15 +1,19 @@
 package main
 
 import (
+	"EPIC-Scouting/lib/calc"
 	"EPIC-Scouting/lib/config"
 	"EPIC-Scouting/lib/db"
 	"EPIC-Scouting/lib/lumberjack"
+	"EPIC-Scouting/lib/scouting"
+	"EPIC-Scouting/lib/tba"
 	"EPIC-Scouting/routes"
 	"fmt"
 	"io"
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
 
 	nice "github.com/ekyoung/gin-nice-recovery"
 	"github.com/gin-contrib/gzip"
@@ -25,7 +29,30 @@ var router *gin.Engine
 func main() {
 	configuration = config.Load()
 	db.TouchBase(configuration.DatabasePath)
+	if configuration.DisagreementTolerance > 0 {
+		calc.DisagreementTolerance = configuration.DisagreementTolerance
+	}
+	if configuration.ReconcileThreshold > 0 {
+		calc.ReconcileThreshold = configuration.ReconcileThreshold
+	}
+	calc.ShareScoringGaps = configuration.ShareScoringGaps
+	if configuration.ReliabilityWeight > 0 && configuration.ReliabilityWeight <= 1 {
+		calc.ReliabilityWeight = configuration.ReliabilityWeight
+	}
+	if configuration.PriorMatches != 0 {
+		calc.PriorMatches = configuration.PriorMatches
+	}
+	tba.DefaultClient.AuthKey = configuration.TBAAuthKey
+	tba.DefaultClient.CacheDir = configuration.DatabasePath + "tba/"
+	tba.WebhookSecret = configuration.TBAWebhookSecret
+	if configuration.TBASyncInterval > 0 {
+		scouting.SyncInterval = time.Duration(configuration.TBASyncInterval) * time.Second
+	}
 	lumberjack.Start(configuration.LogPath, configuration.Verbosity)
+	calc.StartStatsCache()
+	if configuration.TBASyncInterval >= 0 {
+		scouting.StartSync(tba.DefaultClient)
+	}
 	log := lumberjack.New("Main")
 	buildName, buildDate := config.BuildInformation()
 	log.Infof("Scouting system started. Version: %s (%s)", buildName, buildDate)
@@ -64,6 +91,7 @@ func start(port int) {
 
 	router = gin.New()
 	router.Use(gin.Logger())
+	router.Use(routes.QueryCounter())
 	router.Use(gzip.Gzip(gzip.BestCompression)) // Gzip compression.
 	store := cookie.NewStore([]byte("secret"))
 	router.Use(sessions.Sessions("session", store))
@@ -88,6 +116,7 @@ func start(port int) {
 	router.GET("/register", routes.Register)
 	router.POST("/registerPOST", routes.RegisterPOST)
 	router.GET("/scout", routes.Scout)
+	router.GET("/scoutNext", routes.ScoutNext)
 	router.POST("/matchPOST", routes.MatchPOST)
 	router.POST("/pitPOST", routes.PitPOST)
 	router.GET("/sysadmin", routes.SysAdmin)
@@ -96,12 +125,47 @@ func start(port int) {
 	router.GET("/teamCreate", routes.TeamCreate)
 	router.GET("/teamData", routes.TeamData)
 	router.GET("/teamAdmin", routes.TeamAdmin)
+	router.POST("/priorityPOST", routes.PriorityPOST)
+	router.POST("/teamSchedulePOST", routes.TeamSchedulePOST)
+	router.POST("/importEventPOST", routes.ImportEventPOST)
+	router.POST("/buildPriorsPOST", routes.BuildPriorsPOST)
+	router.POST("/matchControlPOST", routes.MatchControlPOST)
+	router.POST("/matchStatusPOST", routes.MatchStatusPOST)
+	router.GET("/coverage", routes.Coverage)
+	router.GET("/coverageGet", routes.CoverageGet)
+	router.POST("/reassignPOST", routes.ReassignPOST)
+	router.GET("/pitPlan", routes.PitPlan)
+	router.POST("/pitPlanPOST", routes.PitPlanPOST)
+	router.POST("/pitStatusPOST", routes.PitStatusPOST)
+	router.GET("/replay", routes.Replay)
+	router.POST("/replayRecordPOST", routes.ReplayRecordPOST)
+	router.POST("/replayStartPOST", routes.ReplayStartPOST)
+	router.POST("/replayEndPOST", routes.ReplayEndPOST)
+	router.POST("/replayReviewPOST", routes.ReplayReviewPOST)
+	router.GET("/shifts", routes.Shifts)
+	router.GET("/shiftsGet", routes.ShiftsGet)
+	router.POST("/shiftRulesPOST", routes.ShiftRulesPOST)
+	router.POST("/shiftGeneratePOST", routes.ShiftGeneratePOST)
+	router.POST("/shiftPOST", routes.ShiftPOST)
 	router.GET("/data", routes.Data)
 	router.GET("/teamDataGet", routes.TeamDataGet)
 	router.GET("/matchDataGet", routes.MatchDataGet)
 	router.GET("/teamMatchDataGet", routes.TeamMatchDataGet)
 	router.GET("/getTeamImages", routes.GetTeamImages)
 	router.GET("/getGraph", routes.GetGraph)
+	router.GET("/disagreements", routes.Disagreements)
+	router.POST("/rulingPOST", routes.RulingPOST)
+	router.GET("/picklist", routes.PickList)
+	router.POST("/picklistPOST", routes.PickListPOST)
+	router.GET("/projection", routes.Projection)
+	router.GET("/projectionGet", routes.ProjectionGet)
+	router.GET("/reconcile", routes.Reconcile)
+	router.POST("/officialScorePOST", routes.OfficialScorePOST)
+	router.GET("/compare", routes.Compare)
+	router.GET("/compareGet", routes.CompareGet)
+	router.GET("/compareGraph", routes.CompareGraph)
+	router.POST("/schedulePOST", routes.SchedulePOST)
+	router.POST("/tbaWebhook", routes.TBAWebhookPOST)
 	log.Debugf("Serving on port %d.", port)
 	log.Fatal(router.Run(address))
 }
@@ -0,0 +1,205 @@
+package routes
+
+import (
+	"EPIC-Scouting/lib/auth"
+	"EPIC-Scouting/lib/calc"
+	"EPIC-Scouting/lib/db"
+	"EPIC-Scouting/lib/web"
+	"bytes"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/wcharczuk/go-chart"
+)
+
+/*
+teamComparison is everything known about one team on the comparison page
+*/
+type teamComparison struct {
+	Team        int
+	Scores      map[string]calc.Stat
+	Breakdowns  map[string][]calc.Stat // Elements are named by calc.BreakdownNames.
+	Trends      map[string]calc.Trend
+	Reliability calc.Reliability
+	Defense     calc.Defense
+	Pit         db.PitData
+}
+
+/*
+comparisonRow is one line of the comparison table, with a value for each team being compared
+*/
+type comparisonRow struct {
+	Name   string
+	Values []string
+}
+
+/*
+Compare shows between two and six teams side by side.
+*/
+func Compare(c *gin.Context) {
+	if auth.CheckLogin(c) == "" {
+		Forbidden(c)
+		return
+	}
+	HeaderData := &web.HeaderData{Title: "Compare Teams", StyleSheets: []string{"global"}}
+	teams, err := compareTeamList(c.Query("teams"))
+	if err != nil {
+		c.HTML(http.StatusOK, "compare.tmpl", gin.H{"HeaderData": HeaderData, "Teams": c.Query("teams"), "Error": err.Error()})
+		return
+	}
+	campaign, event, _ := viewedSchedule(c, activeTeamID(c))
+	comparisons := compareTeams(teams, campaign, event)
+	Rows := make([]comparisonRow, 0)
+	addRow := func(name string, value func(teamComparison) string) {
+		row := comparisonRow{Name: name}
+		for _, comparison := range comparisons {
+			row.Values = append(row.Values, value(comparison))
+		}
+		Rows = append(Rows, row)
+	}
+	for _, name := range []string{"Overall", "Auto", "Shooting", "ColorWheel", "Climbing", "Fouls"} {
+		score := name
+		addRow(score, func(comparison teamComparison) string {
+			return comparison.Scores[score].String()
+		})
+		addRow(score+" Trend", func(comparison teamComparison) string {
+			return fmt.Sprintf("%+.1f per match", comparison.Trends[score].Slope)
+		})
+	}
+	for _, name := range []string{"Auto", "Shooting", "Climbing", "ColorWheel", "Fouls"} {
+		for ind, element := range calc.BreakdownNames[name] {
+			category, index := name, ind
+			addRow(fmt.Sprintf("%s: %s", category, element), func(comparison teamComparison) string {
+				if index >= len(comparison.Breakdowns[category]) {
+					return ""
+				}
+				return comparison.Breakdowns[category][index].String()
+			})
+		}
+	}
+	addRow("Reliability", func(comparison teamComparison) string {
+		return fmt.Sprintf("%.0f", comparison.Reliability.Score)
+	})
+	addRow("Climbs Achieved", func(comparison teamComparison) string {
+		return fmt.Sprintf("%.0f%% of %v", comparison.Reliability.ClimbRate*100, comparison.Reliability.ClimbAttempts)
+	})
+	addRow("Disabled", func(comparison teamComparison) string {
+		return fmt.Sprintf("%.0f%%", comparison.Reliability.DisabledRate*100)
+	})
+	addRow("No Show", func(comparison teamComparison) string {
+		return fmt.Sprintf("%.0f%%", comparison.Reliability.NoShowRate*100)
+	})
+	addRow("Defense", func(comparison teamComparison) string {
+		return comparison.Defense.Rating.String()
+	})
+	addRow("Pit: Team Name", func(comparison teamComparison) string {
+		return comparison.Pit.TeamName
+	})
+	addRow("Pit: Cycle Time", func(comparison teamComparison) string {
+		return strconv.Itoa(comparison.Pit.CycleTime)
+	})
+	addRow("Pit: Notes", func(comparison teamComparison) string {
+		return comparison.Pit.Comments
+	})
+	c.HTML(http.StatusOK, "compare.tmpl", gin.H{"HeaderData": HeaderData, "Teams": c.Query("teams"), "TeamNumbers": teams, "Rows": Rows})
+}
+
+/*
+CompareGet sends everything known about between two and six teams as JSON.
+*/
+func CompareGet(c *gin.Context) {
+	if auth.CheckLogin(c) == "" {
+		Forbidden(c)
+		return
+	}
+	teams, err := compareTeamList(c.Query("teams"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	campaign, event, _ := viewedSchedule(c, activeTeamID(c))
+	c.JSON(http.StatusOK, compareTeams(teams, campaign, event))
+}
+
+/*
+CompareGraph draws one of several teams' scores over the course of the event, overlaid on the same graph.
+*/
+func CompareGraph(c *gin.Context) {
+	if auth.CheckLogin(c) == "" {
+		Forbidden(c)
+		return
+	}
+	teams, err := compareTeamList(c.Query("teams"))
+	if err != nil {
+		c.String(http.StatusBadRequest, err.Error())
+		return
+	}
+	subject := c.Query("subject")
+	if _, ok := calc.Scorers[subject]; !ok {
+		subject = "Overall"
+	}
+	_, event, _ := viewedSchedule(c, activeTeamID(c))
+	series := make([]chart.Series, 0)
+	for _, team := range teams {
+		trend := calc.TeamTrends(team, event)[subject]
+		x := make([]float64, len(trend.MatchNums))
+		for ind, matchNum := range trend.MatchNums {
+			x[ind] = float64(matchNum)
+		}
+		//go-chart cannot draw a series with fewer than two points
+		if len(x) < 2 {
+			continue
+		}
+		series = append(series, chart.ContinuousSeries{Name: strconv.Itoa(team), XValues: x, YValues: trend.Scores})
+	}
+	graph := chart.Chart{
+		XAxis: chart.XAxis{
+			Name: "Match",
+		},
+		YAxis: chart.YAxis{
+			Name: subject,
+		},
+		Series: series,
+	}
+	graph.Elements = []chart.Renderable{chart.Legend(&graph)}
+	imgdata := bytes.NewBuffer([]byte{})
+	graph.Render(chart.PNG, imgdata)
+	c.Data(http.StatusOK, "image/png", imgdata.Bytes())
+}
+
+// compareTeamList reads a comma separated list of two to six team numbers
+func compareTeamList(list string) ([]int, error) {
+	teams := make([]int, 0)
+	for _, field := range strings.Split(list, ",") {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+		team, err := strconv.Atoi(field)
+		if err != nil {
+			return teams, fmt.Errorf("%q is not a team number", field)
+		}
+		if !containsInt(teams, team) {
+			teams = append(teams, team)
+		}
+	}
+	if len(teams) < 2 || len(teams) > 6 {
+		return teams, errors.New("Enter between two and six teams to compare")
+	}
+	return teams, nil
+}
+
+// compareTeams gathers everything known about each team at an event, with pit scouting from its campaign
+func compareTeams(teams []int, campaign, event string) []teamComparison {
+	defenses := calc.DefenseRatings(event)
+	comparisons := make([]teamComparison, 0, len(teams))
+	for _, team := range teams {
+		pit, _ := db.GetPitData(team, campaign)
+		comparisons = append(comparisons, teamComparison{Team: team, Scores: calc.TeamScoreStats(team, event), Breakdowns: calc.TeamBreakdownStats(team, event), Trends: calc.TeamTrends(team, event), Reliability: calc.TeamReliability(team, event), Defense: defenses[team], Pit: pit})
+	}
+	return comparisons
+}
@@ -0,0 +1,109 @@
+package routes
+
+import (
+	"EPIC-Scouting/lib/auth"
+	"EPIC-Scouting/lib/db"
+	"EPIC-Scouting/lib/scouting"
+	"EPIC-Scouting/lib/web"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+/*
+rosterScout is a member of the team who can be reassigned from the coverage board.
+*/
+type rosterScout struct {
+	UserID string
+	Name   string
+}
+
+/*
+Coverage shows the coverage board, which keeps track of whether every robot in the team's current match is being scouted.
+*/
+func Coverage(c *gin.Context) {
+	teamID := activeTeamID(c)
+	if !auth.IsTeamAdmin(c, teamID) {
+		Forbidden(c)
+		return
+	}
+	roster, _ := db.GetTeamMembers(teamID)
+	names := db.UserList()
+	Scouts := make([]rosterScout, len(roster))
+	for ind, userID := range roster {
+		Scouts[ind] = rosterScout{UserID: userID, Name: names[userID]}
+	}
+	HeaderData := &web.HeaderData{Title: "Scouting Coverage", StyleSheets: []string{"global"}}
+	c.HTML(http.StatusOK, "coverage.tmpl", gin.H{"HeaderData": HeaderData, "Scouts": Scouts})
+}
+
+/*
+CoverageGet sends how well each robot in the team's current match is being scouted as JSON, along with the names of the scouts and how many robots nobody is covering.
+*/
+func CoverageGet(c *gin.Context) {
+	teamID := activeTeamID(c)
+	if !auth.IsTeamAdmin(c, teamID) {
+		Forbidden(c)
+		return
+	}
+	match, err := scouting.GetTeamMatch(teamID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	robots, err := scouting.MatchCoverage(teamID, match)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	users := db.UserList()
+	names := make(map[string]string)
+	gaps := 0
+	for _, robot := range robots {
+		if !robot.Covered() {
+			gaps++
+		}
+		for _, scout := range robot.Scouts {
+			names[scout.UserID] = users[scout.UserID]
+		}
+	}
+	c.JSON(http.StatusOK, gin.H{"Match": match, "Robots": robots, "Names": names, "Gaps": gaps})
+}
+
+/*
+ReassignPOST moves a scout to a different robot in the team's current match. The scout's page picks up the change the next time it checks in.
+*/
+func ReassignPOST(c *gin.Context) {
+	teamID := activeTeamID(c)
+	if !auth.IsTeamAdmin(c, teamID) {
+		Forbidden(c)
+		return
+	}
+	c.Request.ParseForm()
+	match, err := scouting.GetTeamMatch(teamID)
+	if err != nil {
+		NotFound(c)
+		return
+	}
+	if c.PostForm("matchid") != match.MatchID {
+		c.String(http.StatusConflict, "Unable to reassign scout: the current match has changed")
+		return
+	}
+	team, err := strconv.Atoi(c.PostForm("team"))
+	if err != nil {
+		c.String(http.StatusBadRequest, "Unable to reassign scout: %q is not a team number", c.PostForm("team"))
+		return
+	}
+	members, _ := db.GetTeamMembers(teamID)
+	if !contains(members, c.PostForm("userid")) {
+		c.String(http.StatusBadRequest, "Unable to reassign scout: %q is not on the team", c.PostForm("userid"))
+		return
+	}
+	err = scouting.Reassign(teamID, c.PostForm("userid"), match, team)
+	if err != nil {
+		c.String(http.StatusBadRequest, "Unable to reassign scout: %s", err.Error())
+		return
+	}
+	c.Redirect(http.StatusSeeOther, "/coverage")
+}
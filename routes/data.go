@@ -1,7 +1,6 @@
 package routes
 
 import (
-	"EPIC-Scouting/lib/auth"
 	"EPIC-Scouting/lib/calc"
 	"EPIC-Scouting/lib/db"
 	"EPIC-Scouting/lib/web"
@@ -21,6 +20,13 @@ type Images struct {
 	Images []string `json:"images"`
 }
 
+//breakdownStat is an element of a team's breakdown for the team profile
+type breakdownStat struct {
+	Category string
+	Name     string
+	Stat     calc.Stat
+}
+
 //Data route for data display
 func Data(c *gin.Context) {
 	querydisplay := c.Query("display")
@@ -33,15 +39,9 @@ func Data(c *gin.Context) {
 	} else if querydisplay == "teamprofile" {
 		var build strings.Builder
 		var comments string
-		teamID, _ := db.GetTeamID(team)
-		campaign, _ := db.GetTeamCampaign(teamID)
-		overall := calc.TeamOverall(team, campaign)
-		auto := calc.TeamAuto(team, campaign)
-		shooting := calc.TeamShooting(team, campaign)
-		colorwheel := calc.TeamColorWheel(team, campaign)
-		climbing := calc.TeamClimbing(team, campaign)
-		fouls := calc.TeamFoul(team, campaign)
-		commentList, _ := db.GetTeamComments(team, campaign)
+		campaign, event, _ := viewedSchedule(c, activeTeamID(c))
+		summary := calc.CachedTeamSummary(team, event)
+		commentList, _ := db.GetTeamComments(team, event)
 		for ind, comment := range commentList {
 			build.WriteString(comment)
 			if ind != len(commentList)-1 {
@@ -49,7 +49,21 @@ func Data(c *gin.Context) {
 			}
 		}
 		comments = build.String()
-		c.HTML(http.StatusOK, "data.tmpl", gin.H{"HeaderData": HeaderData, "TeamProfile": true, "Overall": overall, "Auto": auto, "Shooting": shooting, "ColorWheel": colorwheel, "Climbing": climbing, "Fouls": fouls, "Comments": comments})
+		breakdowns := make([]breakdownStat, 0)
+		for _, category := range []string{"Auto", "Shooting", "ColorWheel", "Climbing", "Fouls"} {
+			for ind, stat := range summary.Breakdowns[category] {
+				breakdowns = append(breakdowns, breakdownStat{Category: category, Name: calc.BreakdownNames[category][ind], Stat: stat})
+			}
+		}
+		defense := calc.CachedDefenseRatings(event)[team]
+		reliability := summary.Reliability
+		pit, _ := db.GetPitData(team, campaign)
+		schedule := calc.TeamScheduleStrength(team, event)
+		reliabilityRates := []string{fmt.Sprintf("%.0f%% of %v attempts", reliability.ClimbRate*100, reliability.ClimbAttempts)}
+		for _, rate := range []float64{reliability.AutoLineRate, reliability.DisabledRate, reliability.TippedRate, reliability.NoShowRate} {
+			reliabilityRates = append(reliabilityRates, fmt.Sprintf("%.0f%%", rate*100))
+		}
+		c.HTML(http.StatusOK, "data.tmpl", gin.H{"HeaderData": HeaderData, "TeamProfile": true, "Overall": summary.Overall, "Auto": summary.Auto, "Shooting": summary.Shooting, "ColorWheel": summary.ColorWheel, "Climbing": summary.Climbing, "Fouls": summary.Fouls, "Comments": comments, "Stats": summary.Stats, "Breakdowns": breakdowns, "Defense": defense, "Reliability": reliability, "Prior": summary.Prior, "ReliabilityRates": reliabilityRates, "Cycle": pit.CycleTime, "Notes": pit.Comments, "Schedule": schedule})
 	} else {
 		c.HTML(http.StatusOK, "data.tmpl", gin.H{"HeaderData": HeaderData, "none": true})
 	}
@@ -61,15 +75,13 @@ func TeamDataGet(c *gin.Context) {
 	var build strings.Builder
 	teamSortKeys := []string{"Team", "Overall", "Auto", "Shooting", "Climing", "Colorwheel", "Fouls"}
 	sortby := c.Query("sortby")
-	userTeam, _ := strconv.Atoi(auth.CheckTeam(c))
-	userTeamID, _ := db.GetTeamID(userTeam)
-	campaign, _ := db.GetTeamCampaign(userTeamID)
+	_, event, _ := viewedSchedule(c, activeTeamID(c))
 	if sortby == "" || !contains(teamSortKeys, sortby) {
 		sortby = "Overall"
 	}
 	searchind := where(teamSortKeys, sortby)
 	//TODO: get each match from database and sort based on the querystring
-	scores := calc.GetTeamScores(campaign)
+	scores := calc.CachedTeamScores(event)
 	for x := len(scores) - 1; x >= 0; x-- {
 		for y := x - 1; y >= 0; y-- {
 			if scores[y][searchind] < scores[x][searchind] {
@@ -79,8 +91,24 @@ func TeamDataGet(c *gin.Context) {
 			}
 		}
 	}
+	defenses := calc.CachedDefenseRatings(event)
 	for ind, score := range scores {
 		build.WriteString(writeCSV(score))
+		//how many matches back the scores, and how far the overall score could be off
+		summary := calc.CachedTeamSummary(score[0], event)
+		overall := summary.Stats["Overall"]
+		interval := "unknown"
+		if overall.Samples > 1 {
+			interval = fmt.Sprintf("%.0f–%.0f", overall.Low, overall.High)
+		}
+		build.WriteString(fmt.Sprintf(",%v,%s", overall.Samples, interval))
+		//how much the team holds opponents below their usual scores
+		defense := "unknown"
+		if rating := defenses[score[0]].Rating; rating.Samples > 0 {
+			defense = fmt.Sprintf("%.1f", rating.Mean)
+		}
+		build.WriteString(fmt.Sprintf(",%s", defense))
+		build.WriteString(fmt.Sprintf(",%.0f", summary.Reliability.Score))
 		if ind != len(score)-1 {
 			build.WriteString("\n")
 		}
@@ -98,12 +126,10 @@ func MatchDataGet(c *gin.Context) {
 	var csvString string
 	var matchResult calc.MatchResults
 	matchResults := make([]calc.MatchResults, 0)
-	userTeam, _ := strconv.Atoi(auth.CheckTeam(c))
-	userTeamID, _ := db.GetTeamID(userTeam)
-	campaign, _ := db.GetTeamCampaign(userTeamID)
-	matchIDs := db.ListMatchIDs(campaign)
+	_, event, _ := viewedSchedule(c, activeTeamID(c))
+	matchIDs := db.GetEventMatchIDs(event)
 	for _, matchID := range matchIDs {
-		matchResult, _ = calc.GetMatchData(matchID)
+		matchResult, _ = calc.CachedMatchData(matchID)
 		matchResults = append(matchResults, matchResult)
 	}
 	for ind, result := range matchResults {
@@ -127,10 +153,8 @@ func TeamMatchDataGet(c *gin.Context) {
 	var matchResult db.MatchData
 	var matches []db.MatchData
 	var participants [][]int
-	userTeam, _ := strconv.Atoi(auth.CheckTeam(c))
-	userTeamID, _ := db.GetTeamID(userTeam)
-	campaign, _ := db.GetTeamCampaign(userTeamID)
-	matchIDs := db.ListMatchIDs(campaign)
+	_, event, _ := viewedSchedule(c, activeTeamID(c))
+	matchIDs := db.GetEventMatchIDs(event)
 	teamNumString := c.Query("team")
 	teamNum, _ := strconv.Atoi(teamNumString)
 	for ind, matchID := range matchIDs {
@@ -221,8 +245,7 @@ GetTeamImages gets a team's images
 */
 func GetTeamImages(c *gin.Context) {
 	var images Images
-	teamID, _ := db.GetTeamID(4415)
-	campaignID, _ := db.GetTeamCampaign(teamID)
+	campaignID, _ := db.GetTeamCampaign(activeTeamID(c))
 	teamNum, _ := strconv.Atoi(c.Query("team"))
 	imageList, _ := db.GetTeamImages(teamNum, campaignID)
 	images.Images = imageList
@@ -237,16 +260,13 @@ func GetGraph(c *gin.Context) {
 	x := make([]float64, 1)
 	y := make([]float64, 1)
 	graphSubject := c.Query("subject")
-	userTeam := c.Query("team")
-	userTeamNum, _ := strconv.Atoi(userTeam)
-	userTeamID, _ := db.GetTeamID(userTeamNum)
-	campaign, _ := db.GetTeamCampaign(userTeamID)
+	_, event, _ := viewedSchedule(c, activeTeamID(c))
 	if graphSubject == "Overall" {
 		xAxis = c.Query("team")
 		yAxis = "Overall"
 		teamNum, _ := strconv.Atoi(xAxis)
-		matches, _ := db.GetTeamMatches(teamNum, campaign)
-		for _, match := range *matches {
+		matches := calc.TeamResults(teamNum, event)
+		for _, match := range matches {
 			_, ok := matchGroups[match.MatchNum]
 			if ok {
 				matchGroups[match.MatchNum] = append(matchGroups[match.MatchNum], match)
@@ -263,8 +283,8 @@ func GetGraph(c *gin.Context) {
 		xAxis = c.Query("team")
 		yAxis = "Auto"
 		teamNum, _ := strconv.Atoi(xAxis)
-		matches, _ := db.GetTeamMatches(teamNum, campaign)
-		for _, match := range *matches {
+		matches := calc.TeamResults(teamNum, event)
+		for _, match := range matches {
 			_, ok := matchGroups[match.MatchNum]
 			if ok {
 				matchGroups[match.MatchNum] = append(matchGroups[match.MatchNum], match)
@@ -281,8 +301,8 @@ func GetGraph(c *gin.Context) {
 		xAxis = c.Query("team")
 		yAxis = "Shooting"
 		teamNum, _ := strconv.Atoi(xAxis)
-		matches, _ := db.GetTeamMatches(teamNum, campaign)
-		for _, match := range *matches {
+		matches := calc.TeamResults(teamNum, event)
+		for _, match := range matches {
 			_, ok := matchGroups[match.MatchNum]
 			if ok {
 				matchGroups[match.MatchNum] = append(matchGroups[match.MatchNum], match)
@@ -299,8 +319,8 @@ func GetGraph(c *gin.Context) {
 		xAxis = c.Query("team")
 		yAxis = "Color Wheel"
 		teamNum, _ := strconv.Atoi(xAxis)
-		matches, _ := db.GetTeamMatches(teamNum, campaign)
-		for _, match := range *matches {
+		matches := calc.TeamResults(teamNum, event)
+		for _, match := range matches {
 			_, ok := matchGroups[match.MatchNum]
 			if ok {
 				matchGroups[match.MatchNum] = append(matchGroups[match.MatchNum], match)
@@ -317,8 +337,8 @@ func GetGraph(c *gin.Context) {
 		xAxis = c.Query("team")
 		yAxis = "Climbing"
 		teamNum, _ := strconv.Atoi(xAxis)
-		matches, _ := db.GetTeamMatches(teamNum, campaign)
-		for _, match := range *matches {
+		matches := calc.TeamResults(teamNum, event)
+		for _, match := range matches {
 			_, ok := matchGroups[match.MatchNum]
 			if ok {
 				matchGroups[match.MatchNum] = append(matchGroups[match.MatchNum], match)
@@ -335,8 +355,8 @@ func GetGraph(c *gin.Context) {
 		xAxis = c.Query("team")
 		yAxis = "Fouls"
 		teamNum, _ := strconv.Atoi(xAxis)
-		matches, _ := db.GetTeamMatches(teamNum, campaign)
-		for _, match := range *matches {
+		matches := calc.TeamResults(teamNum, event)
+		for _, match := range matches {
 			_, ok := matchGroups[match.MatchNum]
 			if ok {
 				matchGroups[match.MatchNum] = append(matchGroups[match.MatchNum], match)
@@ -0,0 +1,95 @@
+package routes
+
+import (
+	"EPIC-Scouting/lib/auth"
+	"EPIC-Scouting/lib/calc"
+	"EPIC-Scouting/lib/db"
+	"EPIC-Scouting/lib/web"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+/*
+disagreementData describes a robot in a match whose scouts disagree, laid out for the disagreements page.
+*/
+type disagreementData struct {
+	MatchNum int
+	Team     int
+	Fields   []string
+	Rows     []disagreementRow
+}
+
+/*
+disagreementRow is one scout's submission, with only the fields the scouts disagree on.
+*/
+type disagreementRow struct {
+	ScoutID string
+	Scout   string
+	Ruling  string
+	Values  []string
+}
+
+/*
+Disagreements shows supervisors every robot whose scouts disagree beyond the tolerance.
+*/
+func Disagreements(c *gin.Context) {
+	teamID := activeTeamID(c)
+	if !auth.IsTeamAdmin(c, teamID) {
+		Forbidden(c)
+		return
+	}
+	tolerance, err := strconv.Atoi(c.Query("tolerance"))
+	if err != nil || tolerance < 0 {
+		tolerance = calc.DisagreementTolerance
+	}
+	_, event, _ := viewedSchedule(c, teamID)
+	scoutNames := db.UserList()
+	Disagreements := make([]disagreementData, 0)
+	for _, disagreement := range calc.FindDisagreements(event, tolerance) {
+		data := disagreementData{MatchNum: disagreement.MatchNum, Team: disagreement.Team, Fields: disagreement.Fields}
+		for _, submission := range disagreement.Submissions {
+			counts := calc.ScoutedCounts(submission)
+			choices := calc.ScoutedChoices(submission)
+			row := disagreementRow{ScoutID: submission.ScoutID, Scout: scoutNames[submission.UserID], Ruling: submission.Ruling}
+			for _, field := range disagreement.Fields {
+				if count, ok := counts[field]; ok {
+					row.Values = append(row.Values, strconv.Itoa(count))
+				} else {
+					row.Values = append(row.Values, choices[field])
+				}
+			}
+			data.Rows = append(data.Rows, row)
+		}
+		Disagreements = append(Disagreements, data)
+	}
+	HeaderData := &web.HeaderData{Title: "Scout Disagreements", StyleSheets: []string{"global"}}
+	c.HTML(http.StatusOK, "disagreements.tmpl", gin.H{"HeaderData": HeaderData, "Tolerance": tolerance, "Disagreements": Disagreements})
+}
+
+/*
+RulingPOST marks a scout's submission authoritative or excluded, or clears the ruling on it.
+*/
+func RulingPOST(c *gin.Context) {
+	teamID := activeTeamID(c)
+	if !auth.IsTeamAdmin(c, teamID) {
+		Forbidden(c)
+		return
+	}
+	c.Request.ParseForm()
+	scoutID := c.PostForm("scoutid")
+	ruling := c.PostForm("ruling")
+	var err error
+	if ruling == "clear" {
+		err = db.ClearResultRuling(scoutID)
+	} else {
+		err = db.SetResultRuling(scoutID, auth.CheckLogin(c), ruling)
+	}
+	if err != nil {
+		c.String(http.StatusBadRequest, "Unable to record ruling: %s", err.Error())
+		return
+	}
+	c.Redirect(http.StatusSeeOther, fmt.Sprintf("/disagreements?tolerance=%s", c.PostForm("tolerance")))
+}
@@ -0,0 +1,184 @@
+package routes
+
+import (
+	"EPIC-Scouting/lib/auth"
+	"EPIC-Scouting/lib/calc"
+	"EPIC-Scouting/lib/db"
+	"EPIC-Scouting/lib/web"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+/*
+pickListData describes a pick list for the pick list page.
+*/
+type pickListData struct {
+	ListType string
+	Entries  []pickListRow
+}
+
+/*
+pickListRow is a team on a pick list, along with its place on the list.
+*/
+type pickListRow struct {
+	Position int
+	Number   int
+	Struck   bool
+}
+
+/*
+pickListChangeData describes a pick list change for the pick list page.
+*/
+type pickListChangeData struct {
+	ListType string
+	User     string
+	Time     string
+	Action   string
+	List     string
+}
+
+/*
+PickList shows the team's first and second pick lists for its active event.
+*/
+func PickList(c *gin.Context) {
+	teamID := activeTeamID(c)
+	if !auth.IsTeamAdmin(c, teamID) {
+		Forbidden(c)
+		return
+	}
+	_, event, err := db.GetTeamSchedule(teamID)
+	if err != nil {
+		NotFound(c)
+		return
+	}
+	firstPicks, _ := db.GetPickList(teamID, event, db.PickListFirst)
+	secondPicks, _ := db.GetPickList(teamID, event, db.PickListSecond)
+	// Suggest from the teams still available on either list, or from every team at the event if the lists are empty.
+	candidates := make([]int, 0)
+	for _, entry := range append(firstPicks, secondPicks...) {
+		if !entry.Struck && !containsInt(candidates, entry.Number) {
+			candidates = append(candidates, entry.Number)
+		}
+	}
+	if len(firstPicks)+len(secondPicks) == 0 {
+		candidates = calc.SeedPickList(event, db.PickListFirst)
+	}
+	ourTeam, _ := db.GetTeamNumber(teamID)
+	Suggestions := calc.SuggestComplements(ourTeam, candidates, event)
+	if len(Suggestions) > 3 {
+		Suggestions = Suggestions[:3]
+	}
+	userNames := db.UserList()
+	history, _ := db.GetPickListHistory(teamID, event)
+	History := make([]pickListChangeData, 0, len(history))
+	for _, change := range history {
+		var list []string
+		for _, entry := range change.List {
+			if entry.Struck {
+				list = append(list, fmt.Sprintf("(%v)", entry.Number))
+			} else {
+				list = append(list, strconv.Itoa(entry.Number))
+			}
+		}
+		History = append(History, pickListChangeData{ListType: change.ListType, User: userNames[change.UserID], Time: change.Time, Action: change.Action, List: writeCSVString(list)})
+	}
+	HeaderData := &web.HeaderData{Title: "Pick List", StyleSheets: []string{"global"}}
+	FirstPicks := newPickListData(db.PickListFirst, firstPicks)
+	SecondPicks := newPickListData(db.PickListSecond, secondPicks)
+	c.HTML(http.StatusOK, "pickList.tmpl", gin.H{"HeaderData": HeaderData, "OurTeam": ourTeam, "FirstPicks": FirstPicks, "SecondPicks": SecondPicks, "Suggestions": Suggestions, "History": History})
+}
+
+/*
+PickListPOST changes a pick list. Actions are seed, add, remove, up, down, strike, and unstrike.
+Striking or unstriking a team changes it on both lists, since a team that has been picked is gone from both.
+*/
+func PickListPOST(c *gin.Context) {
+	teamID := activeTeamID(c)
+	if !auth.IsTeamAdmin(c, teamID) {
+		Forbidden(c)
+		return
+	}
+	c.Request.ParseForm()
+	listType := c.PostForm("list")
+	action := c.PostForm("action")
+	team, _ := strconv.Atoi(c.PostForm("team"))
+	userID := auth.CheckLogin(c)
+	_, event, err := db.GetTeamSchedule(teamID)
+	if err != nil {
+		NotFound(c)
+		return
+	}
+	listTypes := []string{listType}
+	if action == "strike" || action == "unstrike" {
+		listTypes = []string{db.PickListFirst, db.PickListSecond}
+	}
+	for _, listType := range listTypes {
+		list, _ := db.GetPickList(teamID, event, listType)
+		ind := pickListIndex(list, team)
+		description := fmt.Sprintf("%s %v", action, team)
+		switch action {
+		case "seed":
+			list = make([]db.PickListEntry, 0)
+			for _, number := range calc.SeedPickList(event, listType) {
+				list = append(list, db.PickListEntry{Number: number})
+			}
+			description = "seed from scores"
+		case "add":
+			if ind != -1 || team <= 0 {
+				continue
+			}
+			list = append(list, db.PickListEntry{Number: team})
+		case "remove":
+			if ind == -1 {
+				continue
+			}
+			list = append(list[:ind], list[ind+1:]...)
+		case "up":
+			if ind <= 0 {
+				continue
+			}
+			list[ind-1], list[ind] = list[ind], list[ind-1]
+		case "down":
+			if ind == -1 || ind == len(list)-1 {
+				continue
+			}
+			list[ind+1], list[ind] = list[ind], list[ind+1]
+		case "strike", "unstrike":
+			if ind == -1 {
+				continue
+			}
+			list[ind].Struck = action == "strike"
+		default:
+			c.String(http.StatusBadRequest, "Unknown pick list action %q", action)
+			return
+		}
+		err = db.WritePickList(teamID, event, listType, userID, description, list)
+		if err != nil {
+			c.String(http.StatusBadRequest, "Unable to change pick list: %s", err.Error())
+			return
+		}
+	}
+	c.Redirect(http.StatusSeeOther, "/picklist")
+}
+
+// newPickListData numbers the teams on a pick list for display
+func newPickListData(listType string, list []db.PickListEntry) pickListData {
+	data := pickListData{ListType: listType}
+	for ind, entry := range list {
+		data.Entries = append(data.Entries, pickListRow{Position: ind + 1, Number: entry.Number, Struck: entry.Struck})
+	}
+	return data
+}
+
+// pickListIndex finds where a team is on a pick list, or -1 if it isn't on it
+func pickListIndex(list []db.PickListEntry, team int) int {
+	for ind, entry := range list {
+		if entry.Number == team {
+			return ind
+		}
+	}
+	return -1
+}
@@ -0,0 +1,107 @@
+package routes
+
+import (
+	"EPIC-Scouting/lib/auth"
+	"EPIC-Scouting/lib/db"
+	"EPIC-Scouting/lib/scouting"
+	"EPIC-Scouting/lib/web"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+/*
+pitPlanRow is a competitor in the pit scouting plan.
+*/
+type pitPlanRow struct {
+	Team    int
+	Scout   string
+	Mine    bool
+	Status  string
+	Missing string
+}
+
+/*
+PitPlan shows the team's pit scouting plan for its active event, with how far along each competitor is.
+*/
+func PitPlan(c *gin.Context) {
+	teamID := activeTeamID(c)
+	userID := auth.CheckLogin(c)
+	if userID == "" || teamID == "" {
+		Forbidden(c)
+		return
+	}
+	_, eventID, err := db.GetTeamSchedule(teamID)
+	if err != nil {
+		NotFound(c)
+		return
+	}
+	plan, _ := db.GetPitPlan(teamID, eventID)
+	names := db.UserList()
+	Rows := make([]pitPlanRow, len(plan))
+	Counts := make(map[string]int)
+	for ind, entry := range plan {
+		Rows[ind] = pitPlanRow{Team: entry.Team, Scout: names[entry.UserID], Mine: entry.UserID == userID, Status: entry.Status, Missing: strings.Join(entry.Missing, ", ")}
+		Counts[entry.Status]++
+	}
+	roster, _ := db.GetTeamMembers(teamID)
+	Scouts := make([]rosterScout, len(roster))
+	for ind, member := range roster {
+		Scouts[ind] = rosterScout{UserID: member, Name: names[member]}
+	}
+	HeaderData := &web.HeaderData{Title: "Pit Scouting Plan", StyleSheets: []string{"global"}}
+	c.HTML(http.StatusOK, "pitPlan.tmpl", gin.H{"HeaderData": HeaderData, "Rows": Rows, "Counts": Counts, "Statuses": db.PitStatuses, "Scouts": Scouts, "TeamAdmin": auth.IsTeamAdmin(c, teamID)})
+}
+
+/*
+PitPlanPOST splits every competitor at the team's active event among the checked pit scouts, replacing the old plan.
+*/
+func PitPlanPOST(c *gin.Context) {
+	teamID := activeTeamID(c)
+	if !auth.IsTeamAdmin(c, teamID) {
+		Forbidden(c)
+		return
+	}
+	c.Request.ParseForm()
+	_, err := scouting.PlanPitScouting(teamID, c.PostFormArray("pitscout"))
+	if err != nil {
+		c.String(http.StatusBadRequest, "Unable to plan pit scouting: %s", err.Error())
+		return
+	}
+	c.Redirect(http.StatusSeeOther, "/pitPlan")
+}
+
+/*
+PitStatusPOST sets how far along pit scouting of a competitor is, such as to send a scout back for a follow-up.
+*/
+func PitStatusPOST(c *gin.Context) {
+	teamID := activeTeamID(c)
+	if !auth.IsTeamAdmin(c, teamID) {
+		Forbidden(c)
+		return
+	}
+	c.Request.ParseForm()
+	_, eventID, err := db.GetTeamSchedule(teamID)
+	if err != nil {
+		NotFound(c)
+		return
+	}
+	team, err := strconv.Atoi(c.PostForm("team"))
+	if err != nil {
+		c.String(http.StatusBadRequest, "Unable to set pit status: %q is not a team number", c.PostForm("team"))
+		return
+	}
+	status := c.PostForm("status")
+	if !contains(db.PitStatuses, status) {
+		c.String(http.StatusBadRequest, "Unable to set pit status: %q is not a status", status)
+		return
+	}
+	err = db.SetPitStatus(teamID, eventID, team, status)
+	if err != nil {
+		c.String(http.StatusInternalServerError, "Unable to set pit status: %s", err.Error())
+		return
+	}
+	c.Redirect(http.StatusSeeOther, "/pitPlan")
+}
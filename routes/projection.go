@@ -0,0 +1,145 @@
+package routes
+
+import (
+	"EPIC-Scouting/lib/auth"
+	"EPIC-Scouting/lib/calc"
+	"EPIC-Scouting/lib/db"
+	"EPIC-Scouting/lib/web"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+/*
+projectionData describes a team's projected rank for the projection page.
+*/
+type projectionData struct {
+	Team          int
+	RankingPoints int
+	Matches       int
+	MeanRank      string
+	LikelyRanks   string // The range of ranks the team finishes in 80% of the time.
+	TopEight      string
+	Played        string // Strength of the team's completed schedule.
+	Remaining     string
+	Verdict       string
+}
+
+/*
+Projection shows each team's projected final qualification rank.
+*/
+func Projection(c *gin.Context) {
+	if auth.CheckLogin(c) == "" {
+		Forbidden(c)
+		return
+	}
+	teamID := activeTeamID(c)
+	_, event, _ := viewedSchedule(c, teamID)
+	strengths := calc.ScheduleStrengths(event)
+	Projections := make([]projectionData, 0)
+	for _, projection := range calc.ProjectRankings(event, calc.ProjectionSimulations, time.Now().UnixNano()) {
+		low, high := 0, 0
+		var cumulative float64
+		for rank, chance := range projection.RankChances {
+			cumulative += chance
+			if low == 0 && cumulative >= 0.1 {
+				low = rank + 1
+			}
+			if high == 0 && cumulative >= 0.9 {
+				high = rank + 1
+			}
+		}
+		strength := strengths[projection.Team]
+		Projections = append(Projections, projectionData{Team: projection.Team, RankingPoints: projection.RankingPoints, Matches: projection.Remaining, MeanRank: fmt.Sprintf("%.1f", projection.MeanRank), LikelyRanks: fmt.Sprintf("%v–%v", low, high), TopEight: fmt.Sprintf("%.0f%%", projection.TopEight*100), Played: fmt.Sprintf("%+.1f", strength.PlayedStrength), Remaining: fmt.Sprintf("%+.1f", strength.RemainingStrength), Verdict: strength.Verdict})
+	}
+	HeaderData := &web.HeaderData{Title: "Ranking Projection", StyleSheets: []string{"global"}}
+	c.HTML(http.StatusOK, "projection.tmpl", gin.H{"HeaderData": HeaderData, "Simulations": calc.ProjectionSimulations, "Projections": Projections, "TeamAdmin": auth.IsTeamAdmin(c, teamID)})
+}
+
+/*
+ProjectionGet sends each team's projected rank distribution in csv form.
+Each row is the team, its ranking points, its remaining matches, its mean rank, its chance of finishing in the top 8, and then its chance of finishing in each rank.
+At most calc.MaxProjectionSimulations simulations are run, however many are asked for.
+*/
+func ProjectionGet(c *gin.Context) {
+	if auth.CheckLogin(c) == "" {
+		Forbidden(c)
+		return
+	}
+	var build strings.Builder
+	_, event, _ := viewedSchedule(c, activeTeamID(c))
+	simulations, err := strconv.Atoi(c.Query("simulations"))
+	if err != nil {
+		simulations = calc.ProjectionSimulations
+	}
+	if simulations > calc.MaxProjectionSimulations {
+		simulations = calc.MaxProjectionSimulations
+	}
+	projections := calc.ProjectRankings(event, simulations, time.Now().UnixNano())
+	for ind, projection := range projections {
+		csvList := []string{strconv.Itoa(projection.Team), strconv.Itoa(projection.RankingPoints), strconv.Itoa(projection.Remaining), fmt.Sprintf("%.2f", projection.MeanRank), fmt.Sprintf("%.3f", projection.TopEight)}
+		for _, chance := range projection.RankChances {
+			csvList = append(csvList, fmt.Sprintf("%.3f", chance))
+		}
+		build.WriteString(writeCSVString(csvList))
+		if ind != len(projections)-1 {
+			build.WriteString("\n")
+		}
+	}
+	c.Data(http.StatusOK, "text/csv", []byte(build.String()))
+}
+
+/*
+SchedulePOST enters the qualification schedule for the team's active event.
+Each line is a match number followed by the red alliance's three teams and the blue alliance's three teams, separated by commas.
+*/
+func SchedulePOST(c *gin.Context) {
+	teamID := activeTeamID(c)
+	if !auth.IsTeamAdmin(c, teamID) {
+		Forbidden(c)
+		return
+	}
+	c.Request.ParseForm()
+	_, eventID, err := db.GetTeamSchedule(teamID)
+	if err != nil {
+		NotFound(c)
+		return
+	}
+	userID := auth.CheckLogin(c)
+	for _, line := range strings.Split(c.PostForm("schedule"), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		fields := strings.Split(line, ",")
+		numbers := make([]int, 0, len(fields))
+		for _, field := range fields {
+			number, err := strconv.Atoi(strings.TrimSpace(field))
+			if err != nil {
+				c.String(http.StatusBadRequest, "Unable to read schedule line %q: %s", line, err.Error())
+				return
+			}
+			numbers = append(numbers, number)
+		}
+		if len(numbers) != 7 {
+			c.String(http.StatusBadRequest, "Unable to read schedule line %q: expected a match number and six teams", line)
+			return
+		}
+		matchID, err := db.GetMatchID(eventID, userID, numbers[0])
+		if err != nil {
+			c.String(http.StatusBadRequest, "Unable to create match %v: %s", numbers[0], err.Error())
+			return
+		}
+		db.ClearMatchParticipants(matchID)
+		for station := 1; station <= 3; station++ {
+			db.SetMatchParticipant(matchID, numbers[station], "red", station)
+			db.SetMatchParticipant(matchID, numbers[station+3], "blue", station)
+		}
+		calc.InvalidateMatch(eventID, matchID)
+	}
+	c.Redirect(http.StatusSeeOther, "/projection")
+}
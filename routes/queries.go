@@ -0,0 +1,20 @@
+package routes
+
+import (
+	"EPIC-Scouting/lib/db"
+	"EPIC-Scouting/lib/lumberjack"
+
+	"github.com/gin-gonic/gin"
+)
+
+/*
+QueryCounter logs how many database queries ran across the server while each request was handled. The databases don't know which request a query is for, so queries from other requests handled at the same time, and from the stats cache recalculating in the background, are counted too. Counts are only a request's own when nothing else is running, such as when measuring a page on a quiet server.
+*/
+func QueryCounter() gin.HandlerFunc {
+	log := lumberjack.New("Router")
+	return func(c *gin.Context) {
+		before := db.QueryCount()
+		c.Next()
+		log.Debugf("%v database queries ran across the server during %s %s.", db.QueryCount()-before, c.Request.Method, c.Request.URL.Path)
+	}
+}
@@ -0,0 +1,77 @@
+package routes
+
+import (
+	"EPIC-Scouting/lib/auth"
+	"EPIC-Scouting/lib/calc"
+	"EPIC-Scouting/lib/db"
+	"EPIC-Scouting/lib/web"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+/*
+Reconcile shows supervisors every alliance whose scouted points are off from the official score breakdown by more than the threshold.
+*/
+func Reconcile(c *gin.Context) {
+	teamID := activeTeamID(c)
+	if !auth.IsTeamAdmin(c, teamID) {
+		Forbidden(c)
+		return
+	}
+	threshold, err := strconv.Atoi(c.Query("threshold"))
+	if err != nil || threshold < 0 {
+		threshold = calc.ReconcileThreshold
+	}
+	all := c.Query("all") == "true"
+	_, event, _ := viewedSchedule(c, teamID)
+	HeaderData := &web.HeaderData{Title: "Official Score Reconciliation", StyleSheets: []string{"global"}}
+	c.HTML(http.StatusOK, "reconcile.tmpl", gin.H{"HeaderData": HeaderData, "Threshold": threshold, "All": all, "SharingGaps": calc.ShareScoringGaps, "Reconciliations": calc.ReconcileEvent(event, threshold, all)})
+}
+
+/*
+OfficialScorePOST enters an alliance's official score breakdown for a match at the team's active event.
+*/
+func OfficialScorePOST(c *gin.Context) {
+	teamID := activeTeamID(c)
+	if !auth.IsTeamAdmin(c, teamID) {
+		Forbidden(c)
+		return
+	}
+	c.Request.ParseForm()
+	_, eventID, err := db.GetTeamSchedule(teamID)
+	if err != nil {
+		NotFound(c)
+		return
+	}
+	alliance := c.PostForm("alliance")
+	if alliance != "red" && alliance != "blue" {
+		c.String(http.StatusBadRequest, "Unable to record official score: unknown alliance %q", alliance)
+		return
+	}
+	fields := []string{"match", "auto", "teleopcells", "endgame", "fouls", "total"}
+	values := make([]int, len(fields))
+	for ind, field := range fields {
+		values[ind], err = strconv.Atoi(strings.TrimSpace(c.PostForm(field)))
+		if err != nil {
+			c.String(http.StatusBadRequest, "Unable to record official score: %s is not a number", field)
+			return
+		}
+	}
+	matchID, err := db.GetMatchID(eventID, auth.CheckLogin(c), values[0])
+	if err != nil {
+		c.String(http.StatusBadRequest, "Unable to create match %v: %s", values[0], err.Error())
+		return
+	}
+	err = db.StoreOfficialScore(db.OfficialScore{MatchID: matchID, Alliance: alliance, AutoPoints: values[1], TeleopCellPoints: values[2], EndgamePoints: values[3], FoulPoints: values[4], TotalPoints: values[5]})
+	if err != nil {
+		c.String(http.StatusInternalServerError, "Unable to record official score: %s", err.Error())
+		return
+	}
+	//match summaries share out scoring gaps using official scores
+	calc.InvalidateMatch(eventID, matchID)
+	c.Redirect(http.StatusSeeOther, fmt.Sprintf("/reconcile?threshold=%s", c.PostForm("threshold")))
+}
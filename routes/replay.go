@@ -0,0 +1,143 @@
+package routes
+
+import (
+	"EPIC-Scouting/lib/auth"
+	"EPIC-Scouting/lib/db"
+	"EPIC-Scouting/lib/scouting"
+	"EPIC-Scouting/lib/tba"
+	"EPIC-Scouting/lib/web"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+/*
+gradeRow is a trainee's grade from a practice scouting session.
+*/
+type gradeRow struct {
+	Name           string
+	Robots         int
+	Correct        int
+	Checks         int
+	Accuracy       string
+	Mistakes       string
+	CellAlliances  int
+	CellDifference string
+}
+
+/*
+Replay shows the team's practice scouting session on a recorded event. While one is running the team scouts the recorded event, and once it ends each trainee's grade is shown.
+*/
+func Replay(c *gin.Context) {
+	teamID := activeTeamID(c)
+	if !auth.IsTeamAdmin(c, teamID) {
+		Forbidden(c)
+		return
+	}
+	Replays, _ := scouting.ReplayList()
+	session, err := db.GetReplaySession(teamID)
+	Running := err == nil && session.Ended == ""
+	var Current db.ScheduledMatch
+	if Running {
+		Current, _ = scouting.GetTeamMatch(teamID)
+	}
+	Grades := make([]gradeRow, 0)
+	if err == nil && !Running {
+		grades, _ := scouting.GradeReplay(session)
+		names := db.UserList()
+		for _, grade := range grades {
+			mistakes := make([]string, 0, len(grade.Mistakes))
+			for name, count := range grade.Mistakes {
+				mistakes = append(mistakes, fmt.Sprintf("%s: %v", name, count))
+			}
+			sort.Strings(mistakes)
+			Grades = append(Grades, gradeRow{Name: names[grade.UserID], Robots: grade.Robots, Correct: grade.Correct, Checks: grade.Checks, Accuracy: fmt.Sprintf("%.0f%%", grade.Accuracy()*100), Mistakes: strings.Join(mistakes, ", "), CellAlliances: grade.CellAlliances, CellDifference: fmt.Sprintf("%.1f", grade.CellDifference)})
+		}
+	}
+	HeaderData := &web.HeaderData{Title: "Practice Scouting", StyleSheets: []string{"global"}}
+	c.HTML(http.StatusOK, "replay.tmpl", gin.H{"HeaderData": HeaderData, "Replays": Replays, "Session": session, "Running": Running, "Current": Current, "Grades": Grades, "Reviewing": auth.CheckReviewing(c)})
+}
+
+/*
+ReplayRecordPOST saves a past event from The Blue Alliance so it can be replayed without a connection.
+*/
+func ReplayRecordPOST(c *gin.Context) {
+	teamID := activeTeamID(c)
+	if !auth.IsTeamAdmin(c, teamID) {
+		Forbidden(c)
+		return
+	}
+	c.Request.ParseForm()
+	eventKey := strings.ToLower(strings.TrimSpace(c.PostForm("eventkey")))
+	if eventKey == "" {
+		c.String(http.StatusBadRequest, "Unable to record event: no event key was given")
+		return
+	}
+	err := scouting.RecordReplay(tba.DefaultClient, eventKey)
+	if err != nil {
+		c.String(http.StatusBadGateway, "Unable to record event %s: %s", eventKey, err.Error())
+		return
+	}
+	c.Redirect(http.StatusSeeOther, "/replay")
+}
+
+/*
+ReplayStartPOST starts a practice scouting session on a recorded event, moving the team onto it until the session ends.
+*/
+func ReplayStartPOST(c *gin.Context) {
+	teamID := activeTeamID(c)
+	if !auth.IsTeamAdmin(c, teamID) {
+		Forbidden(c)
+		return
+	}
+	c.Request.ParseForm()
+	err := scouting.StartReplay(teamID, c.PostForm("replay"))
+	if err != nil {
+		c.String(http.StatusBadRequest, "Unable to start replay: %s", err.Error())
+		return
+	}
+	c.Redirect(http.StatusSeeOther, "/replay")
+}
+
+/*
+ReplayEndPOST ends the team's practice scouting session, grading its trainees and moving the team back to what it was scouting before.
+*/
+func ReplayEndPOST(c *gin.Context) {
+	teamID := activeTeamID(c)
+	if !auth.IsTeamAdmin(c, teamID) {
+		Forbidden(c)
+		return
+	}
+	_, err := scouting.EndReplay(teamID)
+	if err != nil {
+		c.String(http.StatusBadRequest, "Unable to end replay: %s", err.Error())
+		return
+	}
+	c.Redirect(http.StatusSeeOther, "/replay")
+}
+
+/*
+ReplayReviewPOST points the data pages at the team's last practice scouting session, or back at the event the team is scouting. Only the user who asks is moved, so the rest of the team carries on as before.
+*/
+func ReplayReviewPOST(c *gin.Context) {
+	teamID := activeTeamID(c)
+	if !auth.IsTeamAdmin(c, teamID) {
+		Forbidden(c)
+		return
+	}
+	c.Request.ParseForm()
+	reviewing := c.PostForm("review") == "true"
+	if session, err := db.GetReplaySession(teamID); reviewing && (err != nil || session.Ended == "") {
+		c.String(http.StatusBadRequest, "Unable to review replay: no session has ended")
+		return
+	}
+	auth.SetReviewing(c, reviewing)
+	if reviewing {
+		c.Redirect(http.StatusSeeOther, "/data")
+		return
+	}
+	c.Redirect(http.StatusSeeOther, "/replay")
+}
@@ -3,6 +3,7 @@ package routes
 import (
 	"EPIC-Scouting/lib/auth"
 	"EPIC-Scouting/lib/db"
+	"EPIC-Scouting/lib/scouting"
 	"EPIC-Scouting/lib/web"
 
 	"net/http"
@@ -16,10 +17,14 @@ type PostData struct {
 }
 
 /*
-Scout shows the scout page.
+Scout shows the scout page. Without a type, the team's current scouting mode is shown.
 */
 func Scout(c *gin.Context) {
 	querytype := c.Query("type")
+	if querytype == "" {
+		schedule, _ := db.GetSchedule(activeTeamID(c))
+		querytype = schedule.Mode
+	}
 	if querytype == "match" {
 		HeaderData := &web.HeaderData{Title: "Match Scouting", StyleSheets: []string{"scout"}}
 		c.HTML(http.StatusOK, "scout.tmpl", gin.H{"HeaderData": HeaderData, "MatchScout": true})
@@ -32,14 +37,37 @@ func Scout(c *gin.Context) {
 	}
 }
 
+/*
+ScoutNext sends the logged in scout's current assignment, and where their next one is if they have one, as JSON.
+*/
+func ScoutNext(c *gin.Context) {
+	userID := auth.CheckLogin(c)
+	if userID == "" {
+		Forbidden(c)
+		return
+	}
+	teamID := activeTeamID(c)
+	current, err := scouting.GetScouterMatch(userID, teamID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	db.OpenAssignment(teamID, current.MatchID, userID)
+	response := gin.H{"Current": current}
+	next, err := scouting.GetScouterNextMatch(userID, teamID)
+	if err == nil {
+		response["Next"] = next
+	}
+	c.JSON(http.StatusOK, response)
+}
+
 //MatchPOST processes and stores scouting data from a match
 func MatchPOST(c *gin.Context) {
 	var data PostData
 	c.ShouldBindJSON(&data)
 	//gets uuid to associate with data
 	userID := auth.CheckLogin(c)
-	//TODO: automatically gets 4415's team id - change in future
-	teamID, _ := db.GetTeamID(4415)
+	teamID := activeTeamID(c)
 	if userID != "" {
 		db.StoreMatch(data.Data, userID, teamID)
 	} else {
@@ -52,11 +80,26 @@ func PitPOST(c *gin.Context) {
 	var data PostData
 	c.ShouldBindJSON(&data)
 	userID := auth.CheckLogin(c)
-	teamID, _ := db.GetTeamID(4415)
-	campaignID, _ := db.GetTeamCampaign(teamID)
+	campaignID, _ := db.GetTeamCampaign(activeTeamID(c))
 	if userID != "" {
 		db.WritePitData(data.Data, userID, campaignID)
 	} else {
 		Forbidden(c)
 	}
 }
+
+//activeTeamID gets the id of the scouting team the logged in user is working for. See auth.CheckTeamID
+func activeTeamID(c *gin.Context) string {
+	return auth.CheckTeamID(c)
+}
+
+//viewedSchedule gets the campaign and event the data pages show the logged in user for a team: the sandbox of the team's last practice session while they are reviewing it, otherwise the event the team is scheduled on. See db.GetTeamSchedule
+func viewedSchedule(c *gin.Context, teamID string) (string, string, error) {
+	if auth.CheckReviewing(c) {
+		session, err := db.GetReplaySession(teamID)
+		if err == nil && session.Ended != "" {
+			return session.CampaignID, session.EventID, nil
+		}
+	}
+	return db.GetTeamSchedule(teamID)
+}
@@ -0,0 +1,200 @@
+package routes
+
+import (
+	"EPIC-Scouting/lib/auth"
+	"EPIC-Scouting/lib/db"
+	"EPIC-Scouting/lib/scouting"
+	"EPIC-Scouting/lib/web"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+/*
+shiftScout is a roster member as a column of the shift schedule.
+*/
+type shiftScout struct {
+	UserID  string
+	Name    string
+	PitCrew bool
+	Shifts  int
+}
+
+/*
+shiftCell is whether a scout is on shift for a match.
+*/
+type shiftCell struct {
+	UserID  string
+	OnShift bool
+}
+
+/*
+shiftRow is a match as a row of the shift schedule.
+*/
+type shiftRow struct {
+	MatchID  string
+	MatchNum int
+	OwnMatch bool
+	Short    bool
+	Cells    []shiftCell
+}
+
+/*
+Shifts shows a team's scout shift schedule for its active event, along with the rules it was generated from.
+*/
+func Shifts(c *gin.Context) {
+	teamID := activeTeamID(c)
+	if !auth.IsTeamAdmin(c, teamID) {
+		Forbidden(c)
+		return
+	}
+	_, eventID, err := db.GetTeamSchedule(teamID)
+	if err != nil {
+		NotFound(c)
+		return
+	}
+	schedule, _ := db.GetEventSchedule(eventID)
+	roster, _ := db.GetTeamMembers(teamID)
+	rules, _ := db.GetShiftRules(teamID)
+	shifts, _ := db.GetShifts(teamID, eventID)
+	ownTeam, _ := db.GetTeamNumber(teamID)
+	names := db.UserList()
+	pitCrew := make(map[string]bool)
+	for _, userID := range rules.PitCrew {
+		pitCrew[userID] = true
+	}
+	Scouts := make([]shiftScout, len(roster))
+	for ind, userID := range roster {
+		Scouts[ind] = shiftScout{UserID: userID, Name: names[userID], PitCrew: pitCrew[userID]}
+	}
+	Rows := make([]shiftRow, 0, len(schedule))
+	for _, match := range schedule {
+		onShift := make(map[string]bool)
+		for _, userID := range shifts[match.MatchID] {
+			onShift[userID] = true
+		}
+		row := shiftRow{MatchID: match.MatchID, MatchNum: match.MatchNum, OwnMatch: containsInt(append(append([]int{}, match.Red...), match.Blue...), ownTeam), Short: len(shifts[match.MatchID]) < rules.ScoutsPerMatch}
+		for ind, scout := range Scouts {
+			row.Cells = append(row.Cells, shiftCell{UserID: scout.UserID, OnShift: onShift[scout.UserID]})
+			if onShift[scout.UserID] {
+				Scouts[ind].Shifts++
+			}
+		}
+		Rows = append(Rows, row)
+	}
+	HeaderData := &web.HeaderData{Title: "Scout Shifts", StyleSheets: []string{"global"}}
+	c.HTML(http.StatusOK, "shifts.tmpl", gin.H{"HeaderData": HeaderData, "Rules": rules, "Scouts": Scouts, "Rows": Rows})
+}
+
+/*
+ShiftRulesPOST sets the rules a team's scout shift schedule is generated from. Checked roster members are pit crew.
+*/
+func ShiftRulesPOST(c *gin.Context) {
+	teamID := activeTeamID(c)
+	if !auth.IsTeamAdmin(c, teamID) {
+		Forbidden(c)
+		return
+	}
+	c.Request.ParseForm()
+	rules := db.ShiftRules{PitCrew: c.PostFormArray("pitcrew")}
+	fields := map[string]*int{"scoutspermatch": &rules.ScoutsPerMatch, "maxconsecutive": &rules.MaxConsecutive, "breaklength": &rules.BreakLength}
+	for name, field := range fields {
+		value, err := strconv.Atoi(c.PostForm(name))
+		if err != nil || value < 0 {
+			c.String(http.StatusBadRequest, "Unable to set shift rules: %q is not a valid %s", c.PostForm(name), name)
+			return
+		}
+		*field = value
+	}
+	err := db.SetShiftRules(teamID, rules)
+	if err != nil {
+		c.String(http.StatusInternalServerError, "Unable to set shift rules: %s", err.Error())
+		return
+	}
+	c.Redirect(http.StatusSeeOther, "/shifts")
+}
+
+/*
+ShiftGeneratePOST generates a new scout shift schedule for the team's whole active event, replacing any edits made to the old one.
+*/
+func ShiftGeneratePOST(c *gin.Context) {
+	teamID := activeTeamID(c)
+	if !auth.IsTeamAdmin(c, teamID) {
+		Forbidden(c)
+		return
+	}
+	_, err := scouting.GenerateShifts(teamID)
+	if err != nil {
+		c.String(http.StatusInternalServerError, "Unable to generate shifts: %s", err.Error())
+		return
+	}
+	c.Redirect(http.StatusSeeOther, "/shifts")
+}
+
+/*
+ShiftPOST puts a scout on or takes them off shift for a single match.
+*/
+func ShiftPOST(c *gin.Context) {
+	teamID := activeTeamID(c)
+	if !auth.IsTeamAdmin(c, teamID) {
+		Forbidden(c)
+		return
+	}
+	c.Request.ParseForm()
+	_, eventID, err := db.GetTeamSchedule(teamID)
+	if err != nil {
+		NotFound(c)
+		return
+	}
+	matchID, userID := c.PostForm("matchid"), c.PostForm("userid")
+	if !contains(db.GetEventMatchIDs(eventID), matchID) {
+		c.String(http.StatusBadRequest, "Unable to change shift: %q is not a match at the team's event", matchID)
+		return
+	}
+	members, _ := db.GetTeamMembers(teamID)
+	if !contains(members, userID) {
+		c.String(http.StatusBadRequest, "Unable to change shift: %q is not on the team", userID)
+		return
+	}
+	err = db.SetShift(teamID, eventID, matchID, userID, c.PostForm("onshift") == "true")
+	if err != nil {
+		c.String(http.StatusInternalServerError, "Unable to change shift: %s", err.Error())
+		return
+	}
+	c.Redirect(http.StatusSeeOther, "/shifts")
+}
+
+/*
+ShiftsGet sends a team's scout shift schedule in csv form.
+Each row is a match number followed by the names of the scouts on shift for it.
+*/
+func ShiftsGet(c *gin.Context) {
+	var build strings.Builder
+	teamID := activeTeamID(c)
+	if !auth.IsTeamAdmin(c, teamID) {
+		Forbidden(c)
+		return
+	}
+	_, eventID, err := db.GetTeamSchedule(teamID)
+	if err != nil {
+		NotFound(c)
+		return
+	}
+	schedule, _ := db.GetEventSchedule(eventID)
+	shifts, _ := db.GetShifts(teamID, eventID)
+	names := db.UserList()
+	for ind, match := range schedule {
+		csvList := []string{strconv.Itoa(match.MatchNum)}
+		for _, userID := range shifts[match.MatchID] {
+			csvList = append(csvList, names[userID])
+		}
+		build.WriteString(writeCSVString(csvList))
+		if ind != len(schedule)-1 {
+			build.WriteString("\n")
+		}
+	}
+	c.Header("Content-Disposition", "attachment; filename=shifts.csv")
+	c.Data(http.StatusOK, "text/csv", []byte(build.String()))
+}
@@ -4,6 +4,7 @@ import (
 	"EPIC-Scouting/lib/auth"
 	"EPIC-Scouting/lib/config"
 	"EPIC-Scouting/lib/db"
+	"EPIC-Scouting/lib/scouting"
 	"EPIC-Scouting/lib/web"
 	"fmt"
 	"net/http"
@@ -49,9 +50,27 @@ func SysAdmin(c *gin.Context) {
 	var Teams []string
 	teamList := db.TeamListFull()
 	for id, details := range teamList {
-		Teams = append(Teams, fmt.Sprintf("%s - %s - %s (Scouting match %s at event TODO for campaign TODO)", id, details[0], details[1], details[2]))
+		Teams = append(Teams, fmt.Sprintf("%s - %s - %s (Scouting match %s at event %s for campaign %s)", id, details[0], details[1], details[4], details[3], details[2]))
 	}
-	c.HTML(200, "sysAdmin.tmpl", gin.H{"BuildName": BuildName, "BuildDate": BuildDate, "DatabaseSizes": DatabaseSizes, "SysAdmins": SysAdmins, "Users": Users, "Campaigns": Campaigns, "Teams": Teams, "HeaderData": HeaderData})
+	var TBASync []string
+	syncStatuses, TBASyncStopped := scouting.SyncStatuses()
+	for _, status := range syncStatuses {
+		line := fmt.Sprintf("%s - event %s for campaign %s: ", status.EventKey, status.EventID, status.CampaignID)
+		if status.LastSuccess.IsZero() {
+			line += "never synced"
+		} else {
+			line += "last synced " + status.LastSuccess.Format("2006-01-02 15:04:05")
+		}
+		if status.Stale {
+			line += " from cached data"
+		}
+		if status.Failures > 0 {
+			line += fmt.Sprintf(", failed %v times in a row (%s)", status.Failures, status.Error)
+		}
+		line += ", next sync " + status.NextAttempt.Format("2006-01-02 15:04:05")
+		TBASync = append(TBASync, line)
+	}
+	c.HTML(200, "sysAdmin.tmpl", gin.H{"BuildName": BuildName, "BuildDate": BuildDate, "DatabaseSizes": DatabaseSizes, "SysAdmins": SysAdmins, "Users": Users, "Campaigns": Campaigns, "Teams": Teams, "TBASync": TBASync, "TBASyncStopped": TBASyncStopped, "HeaderData": HeaderData})
 }
 
 /*
@@ -1,16 +1,297 @@
 package routes
 
 import (
+	"EPIC-Scouting/lib/auth"
+	"EPIC-Scouting/lib/calc"
+	"EPIC-Scouting/lib/db"
+	"EPIC-Scouting/lib/scouting"
+	"EPIC-Scouting/lib/tba"
 	"EPIC-Scouting/lib/web"
 	"net/http"
+	"sort"
+	"strconv"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 )
 
+/*
+priorityData is a competitor's scouting priority for the team admin page.
+*/
+type priorityData struct {
+	Team     int
+	Priority int
+}
+
+/*
+controlMatch is a match on the schedule for the team admin's match control.
+*/
+type controlMatch struct {
+	MatchID  string
+	MatchNum int
+	Status   string
+	Current  bool
+}
+
+/*
+scheduleOption is a campaign or event the team could be scouting.
+*/
+type scheduleOption struct {
+	ID       string
+	Name     string
+	Selected bool
+}
+
 /*
 TeamAdmin shows the team administration page.
 */
 func TeamAdmin(c *gin.Context) {
+	teamID := activeTeamID(c)
+	if !auth.IsTeamAdmin(c, teamID) {
+		Forbidden(c)
+		return
+	}
+	Priorities := make([]priorityData, 0)
+	Matches := make([]controlMatch, 0)
+	var Current controlMatch
+	_, eventID, err := db.GetTeamSchedule(teamID)
+	if err == nil {
+		current, _ := scouting.GetTeamMatch(teamID)
+		schedule, _ := db.GetEventSchedule(eventID)
+		for _, match := range schedule {
+			Matches = append(Matches, controlMatch{MatchID: match.MatchID, MatchNum: match.MatchNum, Status: match.Status, Current: match.MatchID == current.MatchID})
+			if match.MatchID == current.MatchID {
+				Current = Matches[len(Matches)-1]
+			}
+		}
+		priorities, _ := db.GetCompetitorPriorities(teamID, eventID)
+		for team, priority := range priorities {
+			Priorities = append(Priorities, priorityData{Team: team, Priority: priority})
+		}
+		sort.Slice(Priorities, func(i, j int) bool {
+			return Priorities[i].Team < Priorities[j].Team
+		})
+	}
+	schedule, _ := db.GetSchedule(teamID)
+	Campaigns := make([]scheduleOption, 0)
+	for id, details := range db.CampaignList() {
+		Campaigns = append(Campaigns, scheduleOption{ID: id, Name: details[1], Selected: id == schedule.CampaignID})
+	}
+	sort.Slice(Campaigns, func(i, j int) bool {
+		return Campaigns[i].Name < Campaigns[j].Name
+	})
+	Events := make([]scheduleOption, 0)
+	events, _ := db.GetCampaignEvents(schedule.CampaignID)
+	for id, name := range events {
+		Events = append(Events, scheduleOption{ID: id, Name: name, Selected: id == schedule.EventID})
+	}
+	sort.Slice(Events, func(i, j int) bool {
+		return Events[i].Name < Events[j].Name
+	})
 	HeaderData := &web.HeaderData{Title: "Team Admin", StyleSheets: []string{"global"}}
-	c.HTML(http.StatusOK, "teamAdmin.tmpl", gin.H{"HeaderData": HeaderData})
+	c.HTML(http.StatusOK, "teamAdmin.tmpl", gin.H{"HeaderData": HeaderData, "Priorities": Priorities, "Current": Current, "Matches": Matches, "Statuses": db.MatchStatuses, "Schedule": schedule, "Campaigns": Campaigns, "Events": Events, "Modes": db.ScoutingModes})
+}
+
+/*
+PriorityPOST sets how much the team wants a competitor at its active event scouted. Higher priority competitors get extra scouts once every robot in a match is covered.
+*/
+func PriorityPOST(c *gin.Context) {
+	teamID := activeTeamID(c)
+	if !auth.IsTeamAdmin(c, teamID) {
+		Forbidden(c)
+		return
+	}
+	c.Request.ParseForm()
+	_, eventID, err := db.GetTeamSchedule(teamID)
+	if err != nil {
+		NotFound(c)
+		return
+	}
+	team, err := strconv.Atoi(c.PostForm("team"))
+	if err != nil {
+		c.String(http.StatusBadRequest, "Unable to set priority: %q is not a team number", c.PostForm("team"))
+		return
+	}
+	priority, err := strconv.Atoi(c.PostForm("priority"))
+	if err != nil || priority < 0 {
+		c.String(http.StatusBadRequest, "Unable to set priority: %q is not a priority", c.PostForm("priority"))
+		return
+	}
+	err = db.SetCompetitorPriority(teamID, eventID, team, priority)
+	if err != nil {
+		c.String(http.StatusInternalServerError, "Unable to set priority: %s", err.Error())
+		return
+	}
+	c.Redirect(http.StatusSeeOther, "/teamAdmin")
+}
+
+/*
+MatchControlPOST moves the match the team is scouting. Action is either advance, back, or jump, which goes straight to the match numbered match.
+Scouts are assigned robots in the new match as soon as they ask for one.
+*/
+func MatchControlPOST(c *gin.Context) {
+	teamID := activeTeamID(c)
+	if !auth.IsTeamAdmin(c, teamID) {
+		Forbidden(c)
+		return
+	}
+	c.Request.ParseForm()
+	var err error
+	switch c.PostForm("action") {
+	case "advance":
+		_, err = scouting.StepMatch(teamID, 1)
+	case "back":
+		_, err = scouting.StepMatch(teamID, -1)
+	case "jump":
+		var matchNum int
+		matchNum, err = strconv.Atoi(c.PostForm("match"))
+		if err != nil {
+			c.String(http.StatusBadRequest, "Unable to change match: %q is not a match number", c.PostForm("match"))
+			return
+		}
+		_, err = scouting.UpdateMatch(teamID, matchNum)
+	default:
+		c.String(http.StatusBadRequest, "Unable to change match: %q is not an action", c.PostForm("action"))
+		return
+	}
+	if err != nil {
+		c.String(http.StatusInternalServerError, "Unable to change match: %s", err.Error())
+		return
+	}
+	c.Redirect(http.StatusSeeOther, "/teamAdmin")
+}
+
+/*
+MatchStatusPOST marks a match at the team's scheduled event as delayed or replayed, or puts it back on schedule if status is empty.
+Marking a match replayed excludes the results scouted so far, so each time it is replayed it is scouted afresh.
+*/
+func MatchStatusPOST(c *gin.Context) {
+	teamID := activeTeamID(c)
+	if !auth.IsTeamAdmin(c, teamID) {
+		Forbidden(c)
+		return
+	}
+	c.Request.ParseForm()
+	status := c.PostForm("status")
+	if status != "" && !contains(db.MatchStatuses, status) {
+		c.String(http.StatusBadRequest, "Unable to set match status: %q is not a status", status)
+		return
+	}
+	_, eventID, err := db.GetTeamSchedule(teamID)
+	if err != nil {
+		c.String(http.StatusBadRequest, "Unable to set match status: the team has no event scheduled")
+		return
+	}
+	matchID := c.PostForm("matchid")
+	if !contains(db.GetEventMatchIDs(eventID), matchID) {
+		c.String(http.StatusBadRequest, "Unable to set match status: %q is not a match at the team's event", matchID)
+		return
+	}
+	err = db.SetMatchStatus(matchID, status)
+	if err != nil {
+		c.String(http.StatusInternalServerError, "Unable to set match status: %s", err.Error())
+		return
+	}
+	if status == "replayed" {
+		err = db.VoidMatchResults(matchID, auth.CheckLogin(c))
+		if err != nil {
+			c.String(http.StatusInternalServerError, "Unable to set match status: %s", err.Error())
+			return
+		}
+	}
+	calc.InvalidateMatch(eventID, matchID)
+	c.Redirect(http.StatusSeeOther, "/teamAdmin")
+}
+
+/*
+TeamSchedulePOST sets which campaign and event the team is scouting and whether it is match or pit scouting.
+Moving to another campaign follows that campaign's active event, and moving to another event starts again from its first unscouted match.
+*/
+func TeamSchedulePOST(c *gin.Context) {
+	teamID := activeTeamID(c)
+	if !auth.IsTeamAdmin(c, teamID) {
+		Forbidden(c)
+		return
+	}
+	c.Request.ParseForm()
+	schedule, _ := db.GetSchedule(teamID)
+	campaignID, eventID, mode := c.PostForm("campaignid"), c.PostForm("eventid"), c.PostForm("mode")
+	if !contains(db.ScoutingModes, mode) {
+		c.String(http.StatusBadRequest, "Unable to set schedule: %q is not a scouting mode", mode)
+		return
+	}
+	if _, ok := db.CampaignList()[campaignID]; !ok {
+		c.String(http.StatusBadRequest, "Unable to set schedule: %q is not a campaign", campaignID)
+		return
+	}
+	if campaignID != schedule.CampaignID {
+		eventID = ""
+	}
+	if eventID != schedule.EventID {
+		schedule.MatchID = ""
+	}
+	schedule.CampaignID, schedule.EventID, schedule.Mode = campaignID, eventID, mode
+	err := db.SetSchedule(teamID, schedule)
+	if err != nil {
+		c.String(http.StatusInternalServerError, "Unable to set schedule: %s", err.Error())
+		return
+	}
+	if _, eventID, err := db.GetTeamSchedule(teamID); err == nil {
+		calc.InvalidateEvent(eventID)
+	}
+	c.Redirect(http.StatusSeeOther, "/teamAdmin")
+}
+
+/*
+ImportEventPOST imports an event's teams and match schedule from The Blue Alliance into a campaign. Importing an event again updates it rather than duplicating it.
+*/
+func ImportEventPOST(c *gin.Context) {
+	teamID := activeTeamID(c)
+	if !auth.IsTeamAdmin(c, teamID) {
+		Forbidden(c)
+		return
+	}
+	c.Request.ParseForm()
+	campaignID, eventKey := c.PostForm("campaignid"), strings.ToLower(strings.TrimSpace(c.PostForm("eventkey")))
+	if _, ok := db.CampaignList()[campaignID]; !ok {
+		c.String(http.StatusBadRequest, "Unable to import event: %q is not a campaign", campaignID)
+		return
+	}
+	if eventKey == "" {
+		c.String(http.StatusBadRequest, "Unable to import event: no event key was given")
+		return
+	}
+	_, err := scouting.ImportEvent(tba.DefaultClient, campaignID, eventKey)
+	if err != nil && err != tba.ErrStale {
+		c.String(http.StatusBadGateway, "Unable to import event %s: %s", eventKey, err.Error())
+		return
+	}
+	c.Redirect(http.StatusSeeOther, "/teamAdmin")
+}
+
+/*
+BuildPriorsPOST rates every team at the team's scheduled event from before the event, from The Blue Alliance, the team's past campaigns, or both. The ratings stand in for scouting until each team's matches are scouted.
+*/
+func BuildPriorsPOST(c *gin.Context) {
+	teamID := activeTeamID(c)
+	if !auth.IsTeamAdmin(c, teamID) {
+		Forbidden(c)
+		return
+	}
+	c.Request.ParseForm()
+	_, eventID, err := db.GetTeamSchedule(teamID)
+	if err != nil || eventID == "" {
+		c.String(http.StatusBadRequest, "Unable to build priors: the team has no event scheduled")
+		return
+	}
+	_, err = scouting.BuildPriors(tba.DefaultClient, teamID, c.PostForm("tba") != "", c.PostForm("campaigns") != "")
+	if err == scouting.ErrNoPriorSource {
+		c.String(http.StatusBadRequest, "Unable to build priors: %s", err.Error())
+		return
+	}
+	if err != nil && err != tba.ErrStale {
+		c.String(http.StatusBadGateway, "Unable to build priors: %s", err.Error())
+		return
+	}
+	c.Redirect(http.StatusSeeOther, "/teamAdmin")
 }
@@ -26,8 +26,18 @@ func TeamCreatePOST(c *gin.Context) {
 	teamCreator := auth.CheckLogin(c)
 	if teamCreator == "" {
 		Forbidden(c)
+		return
 	}
 	teamNum, _ := strconv.Atoi(c.PostForm("number"))
 	teamName := c.PostForm("name")
-	db.TeamCreate(teamNum, teamName, "")
+	err := db.TeamCreate(teamNum, teamName)
+	if err != nil {
+		c.String(http.StatusBadRequest, "Unable to create team: %s", err.Error())
+		return
+	}
+	//whoever creates a team runs it
+	teamID, _ := db.GetTeamID(teamNum)
+	db.AddTeamMember(teamCreator, teamID, "admin")
+	auth.SetTeam(c, teamID)
+	c.Redirect(http.StatusSeeOther, "/teamAdmin")
 }
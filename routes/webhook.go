@@ -0,0 +1,37 @@
+package routes
+
+import (
+	"EPIC-Scouting/lib/lumberjack"
+	"EPIC-Scouting/lib/scouting"
+	"EPIC-Scouting/lib/tba"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+/*
+TBAWebhookPOST receives messages from The Blue Alliance's webhooks. Messages are only accepted when they are signed with the configured webhook secret. See scouting.ReceiveWebhook.
+*/
+func TBAWebhookPOST(c *gin.Context) {
+	log := lumberjack.New("Webhook")
+	body, err := ioutil.ReadAll(c.Request.Body)
+	if err != nil {
+		c.String(http.StatusBadRequest, "Unable to read webhook message: %s", err.Error())
+		return
+	}
+	status, err := scouting.ReceiveWebhook(tba.DefaultClient, body, c.GetHeader(tba.HMACHeader))
+	if status == http.StatusUnauthorized {
+		log.Warnf("Rejected TBA webhook message from %s: %s", c.ClientIP(), err.Error())
+		c.String(status, "Unable to accept webhook message: %s", err.Error())
+		return
+	}
+	if err != nil {
+		if status == http.StatusInternalServerError {
+			log.Errorf("Unable to handle TBA %s", err.Error())
+		}
+		c.String(status, "Unable to handle webhook message: %s", err.Error())
+		return
+	}
+	c.Status(status)
+}
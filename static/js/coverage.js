@@ -0,0 +1,59 @@
+/*
+coverage.js keeps the coverage board up to date with who is scouting each robot in the current match
+*/
+
+var coverageMatch;
+
+function loadCoverage() {
+  var xhttp = new XMLHttpRequest();
+  xhttp.onload = function () {
+    if (xhttp.status != 200) {
+      document.getElementById("coverageMatch").innerText = "No match to show.";
+      return;
+    }
+    var response = JSON.parse(xhttp.responseText);
+    var text = "Match " + response.Match.MatchNum;
+    if (response.Match.Status) {
+      text += " (" + response.Match.Status + ")";
+    }
+    text += ": " + (response.Gaps == 0 ? "every robot is covered." : response.Gaps + " robot(s) have nobody scouting them!");
+    document.getElementById("coverageMatch").innerText = text;
+    var table = document.getElementById("coverage");
+    while (table.rows.length > 1) {
+      table.deleteRow(1);
+    }
+    for (var robot of response.Robots) {
+      var row = table.insertRow();
+      if (robot.Scouts.length == 0 && robot.Submitted == 0) {
+        row.style.backgroundColor = "#f8c0c0";
+      }
+      row.insertCell().innerText = robot.Team;
+      row.insertCell().innerText = robot.Alliance + " " + robot.Station;
+      var scouts = [];
+      for (var scout of robot.Scouts) {
+        var state = scout.Submitted ? "submitted" : (scout.Opened ? "scouting" : "not opened");
+        scouts.push(response.Names[scout.UserID] + " (" + state + ")");
+      }
+      row.insertCell().innerText = scouts.length == 0 ? "none" : scouts.join(", ");
+      row.insertCell().innerText = robot.Submitted;
+    }
+    //only rebuild the robot choices when the match changes, so a reassignment being entered isn't lost
+    if (response.Match.MatchID != coverageMatch) {
+      coverageMatch = response.Match.MatchID;
+      document.getElementById("reassignMatch").value = coverageMatch;
+      var select = document.getElementById("reassignTeam");
+      select.innerHTML = "";
+      for (var robot of response.Robots) {
+        var option = document.createElement("option");
+        option.value = robot.Team;
+        option.text = robot.Team + " (" + robot.Alliance + " " + robot.Station + ")";
+        select.add(option);
+      }
+    }
+  };
+  xhttp.open("GET", "/coverageGet", true);
+  xhttp.send();
+}
+
+loadCoverage();
+setInterval(loadCoverage, 5000);
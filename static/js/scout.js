@@ -3,10 +3,48 @@ scout.js middle man that posts data to the backend server and will show the qr c
 */
 
 var connected;
+var assignedMatch;
+var assignedTeam;
+
+//Fills in the match, team and alliance the scout has been assigned, and tells them where they are scouting next. The form is only filled in again when the match or robot is changed by the team's admins
+function loadAssignment() {
+  var form = document.getElementById("matchForm");
+  var xhttp = new XMLHttpRequest();
+  xhttp.onload = function () {
+    if (xhttp.status != 200) {
+      return;
+    }
+    var response = JSON.parse(xhttp.responseText);
+    var current = response.Current;
+    if (current.MatchID != assignedMatch || current.Team != assignedTeam) {
+      assignedMatch = current.MatchID;
+      assignedTeam = current.Team;
+      form.match.value = current.MatchNum;
+      form.team.value = current.Team;
+      form.alliance.value = current.Alliance == "blue" ? "0" : "1";
+    }
+    var text = "You are scouting " + current.Team + " (" + current.Alliance + " " + current.Station + ") in match " + current.MatchNum + ".";
+    if (response.Next) {
+      if (response.Next.Team) {
+        text += " Next: " + response.Next.Team + " (" + response.Next.Alliance + " " + response.Next.Station + ") in match " + response.Next.MatchNum + ".";
+      } else {
+        text += " Next: match " + response.Next.MatchNum + ".";
+      }
+    }
+    document.getElementById("assignment").innerText = text;
+  };
+  xhttp.open("GET", "/scoutNext", true);
+  xhttp.send();
+}
+
+if (document.getElementById("matchForm") != null) {
+  loadAssignment();
+  setInterval(loadAssignment, 15000);
+}
 
 function submitMatchData(form) {
   //Parse data to CSV
-  var data = [form.match.value, form.team.value, form.alliance.value, form.autoLineCross.value, form.autoLowBalls.value, form.autoHighBalls.value, form.autoBackBalls.value, form.autoShots.value, form.autoPickups.value, form.shotQuantity.value, form.lowFuel.value, form.highFuel.value, form.backFuel.value, form.stageOneComplete.value, form.stageOneTime.value, form.stageTwoComplete.value, form.stageTwoTime.value, form.fouls.value, form.techFouls.value, form.cards.value, form.climbed.value, form.balanced.value, form.climbTime.value, form.comments.value];
+  var data = [form.match.value, form.team.value, form.alliance.value, form.autoLineCross.value, form.autoLowBalls.value, form.autoHighBalls.value, form.autoBackBalls.value, form.autoShots.value, form.autoPickups.value, form.shotQuantity.value, form.lowFuel.value, form.highFuel.value, form.backFuel.value, form.stageOneComplete.value, form.stageOneTime.value, form.stageTwoComplete.value, form.stageTwoTime.value, form.fouls.value, form.techFouls.value, form.cards.value, form.climbed.value, form.balanced.value, form.climbTime.value, form.defenseTime.value, form.defenseQuality.value, form.climbAttempted.value, form.disabled.value, form.tipped.value, form.noShow.value, form.comments.value];
   //Try to post the data to the server
   checkConnection();
   if (connected) {
@@ -26,6 +64,16 @@ function submitPitData(form) {
   //Try to post the data to the server
   var data = [form.team.value, form.teamname.value, form.cycletime.value, form.comments.value];
   var files = form.image.files;
+  //Entries without a photo are still worth sending, the pit scouting plan will ask for one later
+  if (files.length == 0) {
+    checkConnection();
+    if (connected) {
+      xhttp = new XMLHttpRequest();
+      xhttp.open("POST", "/pitPOST", true);
+      xhttp.send(JSON.stringify({data: data}));
+    }
+    return;
+  }
   for (file in files) {
     var reader = new FileReader()
     if (!(files[file] instanceof Blob)) {